@@ -6,7 +6,6 @@ import (
 	"fmt"
 	"go/ast"
 	"go/build"
-	"go/format"
 	"go/parser"
 	"go/printer"
 	"go/token"
@@ -207,23 +206,26 @@ func FindMethods(p *loader.PackageInfo) map[string][]*ast.FuncDecl {
 	return foundMethods
 }
 
-// HasMethod with name n on type t
+// HasMethod with name n on type t.
+// If t is a pointer type, the method set of *T is looked up,
+// otherwise the method set of T, following the rules of the go language.
 func HasMethod(p *loader.PackageInfo, t, n string) bool {
-	foundMethods := FindMethods(p)
-	if y, ok := foundMethods[GetPointedType(t)]; ok {
-		for _, typeMethod := range y {
-			if MethodName(typeMethod) == n {
-				return true
-			}
-		}
-	} else if y, ok := foundMethods[GetUnpointedType(t)]; ok {
-		for _, typeMethod := range y {
-			if MethodName(typeMethod) == n {
-				return true
-			}
-		}
+	onValue, onPointer := TypeHasMethod(p, t, n)
+	if IsAPointedType(t) {
+		return onPointer
 	}
-	return false
+	return onValue
+}
+
+// TypeHasMethod tells if the method n is in the method set of T and in the method set of *T.
+// The type t is always dereferenced.
+// It includes methods promoted from embedded fields.
+func TypeHasMethod(p *loader.PackageInfo, t, n string) (onValue bool, onPointer bool) {
+	ms := FindMethodSet(p, GetUnpointedType(t))
+	if ms == nil {
+		return false, false
+	}
+	return ms.Lookup(n, false) != nil, ms.Lookup(n, true) != nil
 }
 
 // HasStruct with name n
@@ -252,15 +254,18 @@ func FindCtors(p *loader.PackageInfo, aboutTypes []string) map[string]*ast.FuncD
 	return foundCtors
 }
 
-// Print any node x to string
+// Print any node x to string.
+// It uses an empty file set, thus comments might be lost or misplaced,
+// and it returns an empty string for an invalid ast.
+// See Format and FormatNode.
 func Print(x interface{}) string {
-	var b bytes.Buffer
-	fset := token.NewFileSet()
-	format.Node(&b, fset, x)
-	return b.String()
+	ret, _ := Format(token.NewFileSet(), x)
+	return ret
 }
 
 // PrintPkg all files of a package to string.
+// The result is not a valid go file for a package of many files,
+// see PrintPkgFiles and MergePkgFiles.
 func PrintPkg(p *loader.PackageInfo) string {
 	var b bytes.Buffer
 	for _, file := range p.Files {
@@ -274,187 +279,135 @@ func IsExported(m string) bool {
 	return ast.IsExported(m)
 }
 
-// MethodName returns the name of given func
-func MethodName(m *ast.FuncDecl) string {
-	return m.Name.Name
+// MethodName returns the name of given func.
+// The Method* helpers accept the func like nodes supported by GetFunc,
+// an unsupported node, such as the field of an embedded interface,
+// is handled as a func without name, params and results.
+func MethodName(m ast.Node) string {
+	return funcOf(m).Name
 }
 
 // MethodReturnPointer returns true if the func returns a pointer.
-func MethodReturnPointer(m *ast.FuncDecl) bool {
-	if m.Type.Results != nil {
-		for _, p := range m.Type.Results.List {
-			if _, ok := p.Type.(*ast.StarExpr); ok {
-				return true
-			}
+// See GetResults to inspect every result.
+func MethodReturnPointer(m ast.Node) bool {
+	for _, r := range GetResults(nil, m) {
+		if r.IsPointer {
+			return true
 		}
 	}
 	return false
 }
 
 // MethodReturnError returns true if the last out param is of type error.
-func MethodReturnError(m *ast.FuncDecl) bool {
-	if m.Type.Results != nil {
-		for _, p := range m.Type.Results.List {
-			if x, ok := p.Type.(*ast.Ident); ok {
-				if x.Name == "error" {
-					return true
-				}
-			}
-		}
-	}
-	return false
+// See GetResults to use the type information of the package.
+func MethodReturnError(m ast.Node) bool {
+	r := LastResult(nil, m)
+	return r != nil && r.IsError
 }
 
 // MethodReturnTypes returns all types of the out signature.
-func MethodReturnTypes(m *ast.FuncDecl) []string {
-	var ret []string
-	if m.Type.Results != nil {
-		for _, p := range m.Type.Results.List {
-			ret = append(ret, ToString(p.Type))
-		}
-	}
-	return ret
+func MethodReturnTypes(m ast.Node) []string {
+	return GetSignature(m).ResultTypes()
 }
 
 // MethodReturnNames returns all names of the out signature.
-func MethodReturnNames(m *ast.FuncDecl) []string {
+func MethodReturnNames(m ast.Node) []string {
 	var ret []string
-	if m.Type.Results != nil {
-		for _, p := range m.Type.Results.List {
-			if len(p.Names) > 0 {
-				x := ToString(p.Names[0])
-				if x != "" {
-					ret = append(ret, x)
-				}
-			}
+	for _, x := range GetSignature(m).ResultNames() {
+		if x != "" {
+			ret = append(ret, x)
 		}
 	}
 	return ret
 }
 
 // MethodReturnNamesNormalized returns all names of the out signature.
-func MethodReturnNamesNormalized(m *ast.FuncDecl) []string {
+// Unnamed results are given a unique name retVarN, not colliding with other names of m.
+func MethodReturnNamesNormalized(m ast.Node) []string {
 	var ret []string
-	if m.Type.Results != nil {
-		for _, p := range m.Type.Results.List {
-			x := ""
-			if len(p.Names) > 0 {
-				x = ToString(p.Names[0])
-			}
-			if x == "" {
-				x = fmt.Sprintf("retVar%v", retVar)
-				retVar++
-			}
-			ret = append(ret, x)
+	namer := NewFuncNamer(nil, m)
+	for _, x := range GetSignature(m).ResultNames() {
+		if x == "" {
+			x = namer.Next("retVar")
 		}
+		ret = append(ret, x)
 	}
 	return ret
 }
 
-var retVar int
-
 // MethodReturnVars create a list of of unqiue variables for each param of out signature.
-func MethodReturnVars(m *ast.FuncDecl) []string {
+// Variables are named retVarN, not colliding with other names of m.
+func MethodReturnVars(m ast.Node) []string {
 	var ret []string
-	if m.Type.Results != nil {
-		for range m.Type.Results.List {
-			ret = append(ret, fmt.Sprintf("retVar%v", retVar))
-			retVar++
-		}
+	namer := NewFuncNamer(nil, m)
+	for range GetSignature(m).Results {
+		ret = append(ret, namer.Next("retVar"))
 	}
 	return ret
 }
 
 // MethodParamNames reutrns the list of variable in the in signature.
-func MethodParamNames(m *ast.FuncDecl) string {
-	var ret []string
-	for _, p := range m.Type.Params.List {
-		ret = append(ret, p.Names[0].Name)
-	}
-	return strings.Join(ret, ", ")
+func MethodParamNames(m ast.Node) string {
+	return strings.Join(GetSignature(m).ParamNames(), ", ")
 }
 
 // MethodParamTypes reutrns the list of variable type in the in signature.
-func MethodParamTypes(m *ast.FuncDecl) string {
-	var ret []string
-	for _, p := range m.Type.Params.List {
-		ret = append(ret, ToString(p.Type))
-	}
-	return strings.Join(ret, ", ")
+func MethodParamTypes(m ast.Node) string {
+	return strings.Join(GetSignature(m).ParamTypes(), ", ")
 }
 
 // MethodParamNamesInvokation reutrns the list of variable in the in signature as an invokation.
 // If withEllipse is true, the last argument gets uses with the ellipse token.
-func MethodParamNamesInvokation(m *ast.FuncDecl, withEllipse bool) string {
-	var ret []string
-	for _, p := range m.Type.Params.List {
-		ret = append(ret, p.Names[0].Name)
-	}
-	if withEllipse && len(ret) > 0 {
-		ret[len(ret)-1] += "..."
-	}
-	return strings.Join(ret, ", ")
+func MethodParamNamesInvokation(m ast.Node, withEllipse bool) string {
+	return GetSignature(m).Invokation(withEllipse)
 }
 
 // MethodHasEllipse returns true if last param has ellipse.
-func MethodHasEllipse(m *ast.FuncDecl) bool {
-	l := m.Type.Params.List
-	if len(l) > 0 {
-		_, ok := l[len(l)-1].Type.(*ast.Ellipsis)
-		return ok
-	}
-	return false
+func MethodHasEllipse(m ast.Node) bool {
+	return GetSignature(m).HasEllipse()
 }
 
 // MethodParams returns the in signature.
-func MethodParams(m *ast.FuncDecl) string {
-	var ret []string
-	for _, p := range m.Type.Params.List {
-		c := p.Names[0].Name + " "
-		c += ToString(p.Type)
-		ret = append(ret, c)
-	}
-	return strings.Join(ret, ", ")
+func MethodParams(m ast.Node) string {
+	return GetSignature(m).ParamsString()
 }
 
 // MethodParamsToProps returns the in signature as property list.
-func MethodParamsToProps(m *ast.FuncDecl) string {
-	var ret []string
-	for _, p := range m.Type.Params.List {
-		c := p.Names[0].Name + " "
-		switch i := p.Type.(type) {
-		case *ast.Ellipsis:
-			c += "[]" + ToString(i.Elt)
-		default:
-			c += ToString(p.Type)
-		}
-		ret = append(ret, c)
-	}
-	return strings.Join(ret, "\n")
+func MethodParamsToProps(m ast.Node) string {
+	return GetSignature(m).ParamsToProps()
 }
 
 // GetSignatureImportIdentifiers extract import identifers from the method signature.
-func GetSignatureImportIdentifiers(m *ast.FuncDecl) []string {
+func GetSignatureImportIdentifiers(m ast.Node) []string {
 	ret := []string{}
-	paramsType := MethodParamTypes(m)
-	for _, p := range strings.Split(paramsType, ", ") {
-		p = GetPkgID(p)
-		if p != "" {
-			ret = append(ret, p)
-		}
-	}
-	returnsType := MethodReturnTypes(m)
-	for _, p := range returnsType {
-		p = GetPkgID(p)
-		if p != "" {
-			ret = append(ret, p)
+	s := GetSignature(m)
+	for _, p := range append(s.Params, s.Results...) {
+		if p.Type != nil {
+			ret = append(ret, NewTypeExpr(p.Type).Qualifiers()...)
+		} else if x := GetPkgID(p.TypeString); x != "" {
+			ret = append(ret, x)
 		}
 	}
 	return ret
 }
 
-// GetPkgID extract the pkg id in pkg.identifier
+// GetPkgID extract the pkg id in pkg.identifier.
+// Of a type with many package ids, such as map[a.K]b.V, it is the first one, a,
+// use the Qualifiers of ParseTypeExpr to get all of them.
 func GetPkgID(p string) string {
+	if isSimpleType(p) {
+		p = strings.TrimLeft(p, "*[]")
+		if i := strings.Index(p, "."); i > -1 {
+			return p[:i]
+		}
+		return ""
+	}
+	if x, err := ParseTypeExpr(p); err == nil {
+		if q := x.Qualifiers(); len(q) > 0 {
+			return q[0]
+		}
+		return ""
+	}
 	p = strings.TrimSpace(p)
 	x := strings.Split(p, ".")
 	if len(x) > 1 {
@@ -511,14 +464,49 @@ func ReceiverType(x *ast.FuncDecl) string {
 	return ret
 }
 
+// isSimpleType returns true for a type made of pointers, slices and a name, such as *[]pkg.T,
+// the type string helpers handle it without parsing it.
+func isSimpleType(t string) bool {
+	for i := 0; i < len(t); i++ {
+		switch c := t[i]; {
+		case c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9', c == '*':
+		case c == '.':
+			// a qualified name, not an ellipsis.
+			if i == 0 || t[i-1] == '*' || t[i-1] == ']' || t[i-1] == '.' {
+				return false
+			}
+		case c == '[' && i+1 < len(t) && t[i+1] == ']':
+			i++
+		default:
+			return false
+		}
+	}
+	return t != ""
+}
+
 // IsAPointedType returns true for starType.
 func IsAPointedType(t string) bool {
+	if isSimpleType(t) {
+		return t[0] == '*'
+	}
+	if x, err := ParseTypeExpr(t); err == nil {
+		return x.Kind == KindPointer
+	}
 	return len(t) > 0 && t[0] == '*'
 }
 
 // GetUnpointedType always return the dereferenced type.
 // A non pointer types is returned untouched.
 func GetUnpointedType(t string) string {
+	if isSimpleType(t) {
+		return strings.TrimPrefix(t, "*")
+	}
+	if x, err := ParseTypeExpr(t); err == nil {
+		if x.Kind == KindPointer {
+			return x.Elem.String()
+		}
+		return t
+	}
 	if IsAPointedType(t) {
 		return t[1:]
 	}
@@ -536,12 +524,27 @@ func GetPointedType(t string) string {
 
 // IsASlicedType returns true for sliceType.
 func IsASlicedType(t string) bool {
+	if isSimpleType(t) {
+		return strings.HasPrefix(t, "[]")
+	}
+	if x, err := ParseTypeExpr(t); err == nil {
+		return x.Kind == KindSlice
+	}
 	return len(t) > 1 && t[:2] == "[]"
 }
 
 // GetUnslicedType always return the unsliced type.
 // A non pointer types is returned untouched.
 func GetUnslicedType(t string) string {
+	if isSimpleType(t) {
+		return strings.TrimPrefix(t, "[]")
+	}
+	if x, err := ParseTypeExpr(t); err == nil {
+		if x.Kind == KindSlice {
+			return x.Elem.String()
+		}
+		return t
+	}
 	if IsASlicedType(t) {
 		return t[2:]
 	}
@@ -578,9 +581,7 @@ var basicTypes = NewStringSlice().Push(
 // IsBasic return true when the given type is a basic string...
 // The type is always dereferenced.
 func IsBasic(t string) bool {
-	if IsAPointedType(t) {
-		t = t[1:]
-	}
+	t = GetUnpointedType(t)
 	//todo: must have a better way to do this.
 	return basicTypes.Index(t) > -1
 }
@@ -625,14 +626,20 @@ func StructProps(s *ast.StructType) []map[string]string {
 	return ret
 }
 
-// IsArrayType returns true when the given string is an []Array.
+// IsArrayType returns true when the given string is an []Array or a [N]Array.
 func IsArrayType(s string) bool {
+	if isSimpleType(s) {
+		return strings.HasPrefix(s, "[]")
+	}
+	if x, err := ParseTypeExpr(s); err == nil {
+		return x.Kind == KindSlice || x.Kind == KindArray
+	}
 	return len(s) > 0 && s[0] == '['
 }
 
 // IsStarType returns true when the given string is a *Star.
 func IsStarType(s string) bool {
-	return len(s) > 0 && s[0] == '*'
+	return IsAPointedType(s)
 }
 
 // ToString takes an ast.Node and print it to string.
@@ -685,3 +692,20 @@ func GetAnnotations(comment string, start string) map[string]string {
 	}
 	return ret
 }
+
+// HasAnnotation returns true if the comment has a line starting with annotation.
+// annotation includes its start symbol, @readonly.
+// Unlike GetAnnotations, the annotation does not need a value.
+func HasAnnotation(comment string, annotation string) bool {
+	lines := strings.Split(comment, "\n")
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "//") {
+			line = strings.TrimSpace(line[2:])
+		}
+		if line == annotation || strings.HasPrefix(line, annotation+" ") {
+			return true
+		}
+	}
+	return false
+}
@@ -5,9 +5,9 @@ import (
 	"fmt"
 	"go/ast"
 	"go/parser"
-	"go/token"
 	"log"
 	"os"
+	"strings"
 	"testing"
 
 	"golang.org/x/tools/go/loader"
@@ -85,6 +85,43 @@ func TestMethodParams3(t *testing.T) {
 	}
 }
 
+func TestMethodParamsUnnamed(t *testing.T) {
+	y := getFuncDecl(`func t(string, int) {}`)
+	want := "arg0 string, arg1 int"
+	got := MethodParams(y)
+	if want != got {
+		t.Errorf("want %v got %v", want, got)
+	}
+}
+
+func TestMethodParamsGrouped(t *testing.T) {
+	y := getFuncDecl(`func t(a, b string, c ...int) {}`)
+	want := "a string, b string, c ...int"
+	got := MethodParams(y)
+	if want != got {
+		t.Errorf("want %v got %v", want, got)
+	}
+	want = "a, b, c"
+	got = MethodParamNames(y)
+	if want != got {
+		t.Errorf("want %v got %v", want, got)
+	}
+	want = "a string\nb string\nc []int"
+	got = MethodParamsToProps(y)
+	if want != got {
+		t.Errorf("want %v got %v", want, got)
+	}
+}
+
+func TestMethodParamNamesBlank(t *testing.T) {
+	y := getFuncDecl(`func t(_ string, arg0 int, _ ...bool) {}`)
+	want := "arg0_, arg0, arg2..."
+	got := MethodParamNamesInvokation(y, true)
+	if want != got {
+		t.Errorf("want %v got %v", want, got)
+	}
+}
+
 func TestMethodReturnTypes(t *testing.T) {
 	y := getFuncDecl(`func t(r string, v *pointer, y ...string) (y, error) {}`)
 	want := []string{"y", "error"}
@@ -144,6 +181,24 @@ func TestNotMethodReturnError2(t *testing.T) {
 	}
 }
 
+func TestNotMethodReturnError3(t *testing.T) {
+	y := getFuncDecl(`func t() (error, y) {}`)
+	want := false
+	got := MethodReturnError(y)
+	if want != got {
+		t.Errorf("want %v got %v", want, got)
+	}
+}
+
+func TestMethodReturnPointer2(t *testing.T) {
+	y := getFuncDecl(`func t() (y, *z) {}`)
+	want := true
+	got := MethodReturnPointer(y)
+	if want != got {
+		t.Errorf("want %v got %v", want, got)
+	}
+}
+
 func TestStructProps(t *testing.T) {
 	y := getStructDecl(`type t struct{k string}`)
 	props := StructProps(y.Type.(*ast.StructType))
@@ -233,30 +288,157 @@ type T struct{k []string}`)
 	}
 }
 
-func getFuncDecl(s string) *ast.FuncDecl {
-	var buf bytes.Buffer
-	buf.WriteString("package t\n")
-	buf.WriteString(s)
+func TestHasMethod(t *testing.T) {
+	prog := getProgramFromString(`type T struct{}
+func (t T) Value() {}
+func (t *T) Ptr() {}`)
+	pkg := prog.Package("thepackagename")
+	tests := []struct {
+		t    string
+		n    string
+		want bool
+	}{
+		{"T", "Value", true},
+		{"*T", "Value", true},
+		{"T", "Ptr", false},
+		{"*T", "Ptr", true},
+		{"T", "Nop", false},
+	}
+	for _, test := range tests {
+		got := HasMethod(pkg, test.t, test.n)
+		if test.want != got {
+			t.Errorf("%v.%v: want %v got %v", test.t, test.n, test.want, got)
+		}
+	}
+}
+
+func TestTypeHasMethod(t *testing.T) {
+	prog := getProgramFromString(`type T struct{}
+func (t *T) Ptr() {}`)
+	pkg := prog.Package("thepackagename")
+	onValue, onPointer := TypeHasMethod(pkg, "T", "Ptr")
+	if onValue {
+		t.Errorf("want %v got %v", false, onValue)
+	}
+	if !onPointer {
+		t.Errorf("want %v got %v", true, onPointer)
+	}
+}
+
+func TestHasAnnotation(t *testing.T) {
+	comment := "T is a type.\n@readonly\n@name value"
+	tests := []struct {
+		annotation string
+		want       bool
+	}{
+		{"@readonly", true},
+		{"@name", true},
+		{"@read", false},
+		{"@nop", false},
+	}
+	for _, test := range tests {
+		if got := HasAnnotation(comment, test.annotation); test.want != got {
+			t.Errorf("%v: want %v got %v", test.annotation, test.want, got)
+		}
+	}
+}
+
+func TestTypeStringHelpers(t *testing.T) {
+	tests := []struct {
+		t         string
+		pointed   bool
+		sliced    bool
+		array     bool
+		unpointed string
+		unsliced  string
+		pkgID     string
+	}{
+		{"*pkg.T", true, false, false, "pkg.T", "*pkg.T", "pkg"},
+		{"[]T", false, true, true, "[]T", "T", ""},
+		{"[5]int", false, false, true, "[5]int", "[5]int", ""},
+		{"map[string]*T", false, false, false, "map[string]*T", "map[string]*T", ""},
+		{"map[string]*pkg.T", false, false, false, "map[string]*pkg.T", "map[string]*pkg.T", "pkg"},
+		{"chan *T", false, false, false, "chan *T", "chan *T", ""},
+		{"func() *T", false, false, false, "func() *T", "func() *T", ""},
+		{"*[]*pkg.T", true, false, false, "[]*pkg.T", "*[]*pkg.T", "pkg"},
+		{"[]*pkg.T", false, true, true, "[]*pkg.T", "*pkg.T", "pkg"},
+		{"...pkg.T", false, false, false, "...pkg.T", "...pkg.T", "pkg"},
+		{"func(a.A) b.B", false, false, false, "func(a.A) b.B", "func(a.A) b.B", "a"},
+		{"map[a.K]b.V", false, false, false, "map[a.K]b.V", "map[a.K]b.V", "a"},
+		{"T", false, false, false, "T", "T", ""},
+		{"**T", true, false, false, "*T", "**T", ""},
+		{"[][]pkg.T", false, true, true, "[][]pkg.T", "[]pkg.T", "pkg"},
+	}
+	for _, test := range tests {
+		if got := IsAPointedType(test.t); test.pointed != got {
+			t.Errorf("IsAPointedType(%v): want %v got %v", test.t, test.pointed, got)
+		}
+		if got := IsStarType(test.t); test.pointed != got {
+			t.Errorf("IsStarType(%v): want %v got %v", test.t, test.pointed, got)
+		}
+		if got := IsASlicedType(test.t); test.sliced != got {
+			t.Errorf("IsASlicedType(%v): want %v got %v", test.t, test.sliced, got)
+		}
+		if got := IsArrayType(test.t); test.array != got {
+			t.Errorf("IsArrayType(%v): want %v got %v", test.t, test.array, got)
+		}
+		if got := GetUnpointedType(test.t); test.unpointed != got {
+			t.Errorf("GetUnpointedType(%v): want %v got %v", test.t, test.unpointed, got)
+		}
+		if got := GetUnslicedType(test.t); test.unsliced != got {
+			t.Errorf("GetUnslicedType(%v): want %v got %v", test.t, test.unsliced, got)
+		}
+		if got := GetPkgID(test.t); test.pkgID != got {
+			t.Errorf("GetPkgID(%v): want %v got %v", test.t, test.pkgID, got)
+		}
+	}
+}
 
-	fset := token.NewFileSet()
-	x, err := parser.ParseFile(fset, "nop.go", &buf, 0)
+func TestIsSimpleType(t *testing.T) {
+	for _, s := range []string{"T", "*pkg.T", "[]*pkg.T", "*[]T"} {
+		if !isSimpleType(s) {
+			t.Errorf("%v must be a simple type", s)
+		}
+	}
+	for _, s := range []string{"", "...pkg.T", "[5]T", "map[K]V", "chan T", "func()", "pkg.T[int]", " T"} {
+		if isSimpleType(s) {
+			t.Errorf("%v must not be a simple type", s)
+		}
+	}
+}
+
+func TestGetSignatureImportIdentifiersFuncParam(t *testing.T) {
+	m := getFuncDecl(`func (t *Tomate) Hello(f func(a.A) *b.B, m map[c.K][]string) error { return nil }`)
+	want := "a b c"
+	if got := strings.Join(GetSignatureImportIdentifiers(m), " "); want != got {
+		t.Errorf("want %v got %v", want, got)
+	}
+}
+
+// assertCompiles reports an error if the generated file src
+// does not type check along the package thepackagename of prog.
+func assertCompiles(t *testing.T, prog *loader.Program, src []byte) {
+	t.Helper()
+	err := CheckGenerated(prog, prog.Package("thepackagename"), map[string][]byte{"gen.go": src})
+	if err != nil {
+		t.Errorf("generated code does not compile %v\n%s", err, src)
+	}
+}
+
+func getFuncDecl(s string) *ast.FuncDecl {
+	x, err := ParseDecl(s)
 	if err != nil {
 		panic(err)
 	}
-	return x.Decls[0].(*ast.FuncDecl)
+	return x.(*ast.FuncDecl)
 }
 
 func getStructDecl(s string) *ast.TypeSpec {
-	var buf bytes.Buffer
-	buf.WriteString("package t\n")
-	buf.WriteString(s)
-
-	fset := token.NewFileSet()
-	x, err := parser.ParseFile(fset, "nop.go", &buf, 0)
+	x, err := ParseDecl(s)
 	if err != nil {
 		panic(err)
 	}
-	return x.Decls[0].(*ast.GenDecl).Specs[0].(*ast.TypeSpec)
+	return x.(*ast.GenDecl).Specs[0].(*ast.TypeSpec)
 }
 
 func getProgramFromString(s string) *loader.Program {
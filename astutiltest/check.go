@@ -0,0 +1,28 @@
+package astutiltest
+
+import (
+	"go/scanner"
+	"testing"
+
+	"github.com/mh-cbon/astutil"
+)
+
+// Check loads the package of the directory dir, runs gen
+// and type checks the generated go files together with the package,
+// each type error is reported with its position in the generated files.
+func Check(t testing.TB, dir string, gen Generator) {
+	t.Helper()
+	prog, pkg := Load(t, dir)
+	out, err := gen(prog, pkg)
+	if err != nil {
+		t.Fatalf("%v: %v", dir, err)
+	}
+	err = astutil.CheckGenerated(prog, pkg, out)
+	if list, ok := err.(scanner.ErrorList); ok {
+		for _, e := range list {
+			t.Errorf("%v: %v", dir, e)
+		}
+	} else if err != nil {
+		t.Errorf("%v: %v", dir, err)
+	}
+}
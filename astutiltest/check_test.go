@@ -0,0 +1,27 @@
+package astutiltest
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/loader"
+)
+
+func TestCheck(t *testing.T) {
+	Check(t, "testdata/basic", interfaceGen)
+}
+
+func TestCheckError(t *testing.T) {
+	r := &recorder{TB: t}
+	Check(r, "testdata/basic", func(prog *loader.Program, pkg *loader.PackageInfo) (map[string][]byte, error) {
+		return map[string][]byte{
+			"tomate_gen.go": []byte("package basic\n\nfunc NewTomate() Tomate {\n\treturn &Tomate{}\n}\n"),
+		}, nil
+	})
+	if len(r.errs) != 1 {
+		t.Fatalf("want %v got %v errors %q", 1, len(r.errs), r.errs)
+	}
+	if !strings.Contains(r.errs[0], "tomate_gen.go:4:") {
+		t.Errorf("unexpected error %v", r.errs[0])
+	}
+}
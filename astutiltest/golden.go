@@ -0,0 +1,154 @@
+// Package astutiltest provides helpers to test generators built with astutil.
+package astutiltest
+
+import (
+	"flag"
+	"go/ast"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/mh-cbon/astutil"
+	"golang.org/x/tools/go/loader"
+)
+
+// Update regenerates the golden files instead of comparing them.
+// The golden files are also regenerated when the environment variable
+// ASTUTILTEST_UPDATE is set, or when the test package defines an -update flag
+// which is set, such as go test -update.
+// astutiltest does not define the flag, to not conflict with the test packages.
+var Update bool
+
+// UpdateEnv is the environment variable enabling Update.
+const UpdateEnv = "ASTUTILTEST_UPDATE"
+
+// updating returns true if the golden files must be regenerated.
+func updating() bool {
+	if Update || os.Getenv(UpdateEnv) != "" {
+		return true
+	}
+	if f := flag.Lookup("update"); f != nil {
+		ok, _ := strconv.ParseBool(f.Value.String())
+		return ok
+	}
+	return false
+}
+
+// GoldenExt is the extension of the golden files.
+const GoldenExt = ".golden"
+
+// Generator generates files from the package pkg of prog,
+// it returns the content of the files by name.
+type Generator func(prog *loader.Program, pkg *loader.PackageInfo) (map[string][]byte, error)
+
+// Load loads the go files of the directory dir, such as testdata/basic,
+// as a package, test files are ignored.
+func Load(t testing.TB, dir string) (*loader.Program, *loader.PackageInfo) {
+	t.Helper()
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf := astutil.GetProgramLoader(dir)
+	var parsed []*ast.File
+	for _, f := range files {
+		if strings.HasSuffix(f, "_test.go") {
+			continue
+		}
+		x, err := conf.ParseFile(f, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		parsed = append(parsed, x)
+	}
+	if len(parsed) == 0 {
+		t.Fatalf("no go files in %v", dir)
+	}
+	conf.CreateFromFiles(parsed[0].Name.Name, parsed...)
+	prog, err := conf.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return prog, prog.Created[0]
+}
+
+// Golden loads the package of the directory dir, runs gen
+// and compares each generated file to its golden file dir/name.golden.
+// Differences, missing and extra golden files are reported as errors with a diff.
+// With Update, the golden files are written instead.
+func Golden(t testing.TB, dir string, gen Generator) {
+	t.Helper()
+	prog, pkg := Load(t, dir)
+	out, err := gen(prog, pkg)
+	if err != nil {
+		t.Fatalf("%v: %v", dir, err)
+	}
+	goldens, err := filepath.Glob(filepath.Join(dir, "*"+GoldenExt))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updating() {
+		for _, g := range goldens {
+			if err := os.Remove(g); err != nil {
+				t.Fatal(err)
+			}
+		}
+		for name, src := range out {
+			if err := ioutil.WriteFile(filepath.Join(dir, name+GoldenExt), src, 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return
+	}
+	for _, g := range goldens {
+		name := strings.TrimSuffix(filepath.Base(g), GoldenExt)
+		if _, ok := out[name]; !ok {
+			t.Errorf("%v: %v was not generated, set ASTUTILTEST_UPDATE=1 to remove its golden file", dir, name)
+		}
+	}
+	var names []string
+	for name := range out {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		g := filepath.Join(dir, name+GoldenExt)
+		want, err := ioutil.ReadFile(g)
+		if os.IsNotExist(err) {
+			t.Errorf("%v: %v has no golden file, set ASTUTILTEST_UPDATE=1 to create it", dir, name)
+			continue
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		if diff := astutil.UnifiedDiff(g, name, want, out[name]); diff != "" {
+			t.Errorf("%v: %v differs from its golden file, set ASTUTILTEST_UPDATE=1 to accept it\n%v", dir, name, diff)
+		}
+	}
+}
+
+// GoldenDirs runs Golden as a subtest for each directory of root.
+func GoldenDirs(t *testing.T, root string, gen Generator) {
+	t.Helper()
+	dirs, err := ioutil.ReadDir(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := 0
+	for _, d := range dirs {
+		if !d.IsDir() {
+			continue
+		}
+		n++
+		dir := filepath.Join(root, d.Name())
+		t.Run(d.Name(), func(t *testing.T) {
+			Golden(t, dir, gen)
+		})
+	}
+	if n == 0 {
+		t.Fatalf("no test directories in %v", root)
+	}
+}
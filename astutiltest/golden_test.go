@@ -0,0 +1,90 @@
+package astutiltest
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mh-cbon/astutil"
+	"golang.org/x/tools/go/loader"
+)
+
+var _ = flag.Bool("update", false, "update the golden files")
+
+func interfaceGen(prog *loader.Program, pkg *loader.PackageInfo) (map[string][]byte, error) {
+	src, err := astutil.GenerateInterface(pkg, "Tomate", astutil.InterfaceOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return map[string][]byte{"tomate_iface.go": src}, nil
+}
+
+func TestGoldenDirs(t *testing.T) {
+	GoldenDirs(t, "testdata", interfaceGen)
+}
+
+// recorder records the errors of a test.
+type recorder struct {
+	testing.TB
+	errs []string
+}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errs = append(r.errs, fmt.Sprintf(format, args...))
+}
+
+func TestGoldenDiff(t *testing.T) {
+	if updating() {
+		t.Skip()
+	}
+	r := &recorder{TB: t}
+	Golden(r, "testdata/basic", func(prog *loader.Program, pkg *loader.PackageInfo) (map[string][]byte, error) {
+		out, err := interfaceGen(prog, pkg)
+		if err != nil {
+			return nil, err
+		}
+		out["tomate_iface.go"] = []byte(strings.Replace(string(out["tomate_iface.go"]), "GetName()", "Name()", -1))
+		out["other.go"] = []byte("package basic\n")
+		return out, nil
+	})
+	if len(r.errs) != 2 {
+		t.Fatalf("want %v got %v errors %q", 2, len(r.errs), r.errs)
+	}
+	if !strings.Contains(r.errs[0], "other.go has no golden file") {
+		t.Errorf("unexpected error %v", r.errs[0])
+	}
+	if !strings.Contains(r.errs[1], "-\tGetName() string\n+\tName() string\n") {
+		t.Errorf("unexpected error %v", r.errs[1])
+	}
+}
+
+func TestGoldenUpdateEnv(t *testing.T) {
+	dir, err := ioutil.TempDir("", "astutiltest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	src, err := ioutil.ReadFile("testdata/basic/tomate.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "tomate.go"), src, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if old, ok := os.LookupEnv(UpdateEnv); ok {
+		defer os.Setenv(UpdateEnv, old)
+	} else {
+		defer os.Unsetenv(UpdateEnv)
+	}
+	if err := os.Setenv(UpdateEnv, "1"); err != nil {
+		t.Fatal(err)
+	}
+	Golden(t, dir, interfaceGen)
+	if _, err := os.Stat(filepath.Join(dir, "tomate_iface.go"+GoldenExt)); err != nil {
+		t.Errorf("the golden file was not written: %v", err)
+	}
+}
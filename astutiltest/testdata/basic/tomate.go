@@ -0,0 +1,18 @@
+package basic
+
+import "io"
+
+// Tomate is a test type.
+type Tomate struct {
+	Name string
+}
+
+// Hello says hello.
+func (t *Tomate) Hello(w io.Writer) error {
+	return nil
+}
+
+// GetName returns the name.
+func (t Tomate) GetName() string {
+	return t.Name
+}
@@ -0,0 +1,394 @@
+package astutil
+
+import (
+	"go/ast"
+	"go/token"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Ident creates an identifier.
+func Ident(name string) *ast.Ident {
+	return ast.NewIdent(name)
+}
+
+// Nil creates the nil identifier.
+func Nil() *ast.Ident {
+	return ast.NewIdent("nil")
+}
+
+// Type creates the type expression s, such as map[string]*pkg.T.
+// An invalid type expression results in an *ast.BadExpr, Format reports it as an error.
+func Type(s string) ast.Expr {
+	ret, err := ParseType(s)
+	if err != nil {
+		return &ast.BadExpr{}
+	}
+	return ret
+}
+
+// Sel creates the selector expression x.sel[0].sel[1]...
+func Sel(x ast.Expr, sel ...string) ast.Expr {
+	for _, s := range sel {
+		x = &ast.SelectorExpr{X: x, Sel: ast.NewIdent(s)}
+	}
+	return x
+}
+
+// Call creates the call expression fun(args...).
+func Call(fun ast.Expr, args ...ast.Expr) *ast.CallExpr {
+	return &ast.CallExpr{Fun: fun, Args: args}
+}
+
+// CallVariadic creates the call expression fun(args...), its last argument has an ellipsis.
+func CallVariadic(fun ast.Expr, args ...ast.Expr) *ast.CallExpr {
+	ret := Call(fun, args...)
+	ret.Ellipsis = 1
+	return ret
+}
+
+// Star creates the expression *x.
+func Star(x ast.Expr) *ast.StarExpr {
+	return &ast.StarExpr{X: x}
+}
+
+// Addr creates the expression &x.
+func Addr(x ast.Expr) *ast.UnaryExpr {
+	return &ast.UnaryExpr{Op: token.AND, X: x}
+}
+
+// Not creates the expression !x.
+func Not(x ast.Expr) *ast.UnaryExpr {
+	return &ast.UnaryExpr{Op: token.NOT, X: x}
+}
+
+// Binary creates the expression x op y.
+func Binary(x ast.Expr, op token.Token, y ast.Expr) *ast.BinaryExpr {
+	return &ast.BinaryExpr{X: x, Op: op, Y: y}
+}
+
+// Index creates the expression x[i].
+func Index(x, i ast.Expr) *ast.IndexExpr {
+	return &ast.IndexExpr{X: x, Index: i}
+}
+
+// Str creates a string literal.
+func Str(s string) *ast.BasicLit {
+	return &ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(s)}
+}
+
+// Int creates an int literal.
+func Int(i int) *ast.BasicLit {
+	return &ast.BasicLit{Kind: token.INT, Value: strconv.Itoa(i)}
+}
+
+// Composite creates the composite literal t{elts...}.
+func Composite(t ast.Expr, elts ...ast.Expr) *ast.CompositeLit {
+	return &ast.CompositeLit{Type: t, Elts: elts}
+}
+
+// KeyValue creates the expression key: value of a composite literal.
+func KeyValue(key, value ast.Expr) *ast.KeyValueExpr {
+	return &ast.KeyValueExpr{Key: key, Value: value}
+}
+
+// TypeToStructInit creates the initialization of the type t,
+// &pkg.Struct{} for *pkg.Struct, pkg.Struct{} otherwise.
+func TypeToStructInit(t ast.Expr) ast.Expr {
+	if x, ok := t.(*ast.StarExpr); ok {
+		return Addr(Composite(x.X))
+	}
+	return Composite(t)
+}
+
+// ExprStmt creates the statement of the expression x.
+func ExprStmt(x ast.Expr) *ast.ExprStmt {
+	return &ast.ExprStmt{X: x}
+}
+
+// Assign creates the assignment lhs tok rhs, where tok is =, := or an assign operator.
+func Assign(tok token.Token, lhs []ast.Expr, rhs ...ast.Expr) *ast.AssignStmt {
+	return &ast.AssignStmt{Lhs: lhs, Tok: tok, Rhs: rhs}
+}
+
+// Set creates the assignment lhs = rhs.
+func Set(lhs, rhs ast.Expr) *ast.AssignStmt {
+	return Assign(token.ASSIGN, []ast.Expr{lhs}, rhs)
+}
+
+// Define creates the short variable declaration names := rhs.
+func Define(names []string, rhs ...ast.Expr) *ast.AssignStmt {
+	var lhs []ast.Expr
+	for _, n := range names {
+		lhs = append(lhs, ast.NewIdent(n))
+	}
+	return Assign(token.DEFINE, lhs, rhs...)
+}
+
+// Return creates a return statement.
+func Return(results ...ast.Expr) *ast.ReturnStmt {
+	return &ast.ReturnStmt{Results: results}
+}
+
+// Block creates a block statement.
+func Block(stmts ...ast.Stmt) *ast.BlockStmt {
+	return &ast.BlockStmt{List: stmts}
+}
+
+// For creates the loop for init; cond; post { body }, init, cond and post can be nil.
+func For(init ast.Stmt, cond ast.Expr, post ast.Stmt, body ...ast.Stmt) *ast.ForStmt {
+	return &ast.ForStmt{Init: init, Cond: cond, Post: post, Body: Block(body...)}
+}
+
+// Range creates the loop for key, value := range x { body }, key and value can be empty.
+func Range(key, value string, x ast.Expr, body ...ast.Stmt) *ast.RangeStmt {
+	ret := &ast.RangeStmt{X: x, Body: Block(body...)}
+	if key != "" || value != "" {
+		ret.Tok = token.DEFINE
+		if key == "" {
+			key = "_"
+		}
+		ret.Key = ast.NewIdent(key)
+	}
+	if value != "" {
+		ret.Value = ast.NewIdent(value)
+	}
+	return ret
+}
+
+// IfBuilder builds an if statement.
+type IfBuilder struct {
+	stmt *ast.IfStmt
+	last *ast.IfStmt
+}
+
+// BuildIf starts the statement if cond { body }.
+func BuildIf(cond ast.Expr, body ...ast.Stmt) *IfBuilder {
+	ret := &ast.IfStmt{Cond: cond, Body: Block(body...)}
+	return &IfBuilder{stmt: ret, last: ret}
+}
+
+// Init sets the init statement of the if.
+func (b *IfBuilder) Init(init ast.Stmt) *IfBuilder {
+	b.stmt.Init = init
+	return b
+}
+
+// ElseIf adds the branch else if cond { body }.
+func (b *IfBuilder) ElseIf(cond ast.Expr, body ...ast.Stmt) *IfBuilder {
+	x := &ast.IfStmt{Cond: cond, Body: Block(body...)}
+	b.last.Else = x
+	b.last = x
+	return b
+}
+
+// Else adds the branch else { body }.
+func (b *IfBuilder) Else(body ...ast.Stmt) *IfBuilder {
+	b.last.Else = Block(body...)
+	return b
+}
+
+// Stmt returns the if statement.
+func (b *IfBuilder) Stmt() *ast.IfStmt {
+	return b.stmt
+}
+
+// SwitchBuilder builds a switch statement.
+type SwitchBuilder struct {
+	stmt *ast.SwitchStmt
+}
+
+// BuildSwitch starts the statement switch tag {}, tag can be nil.
+func BuildSwitch(tag ast.Expr) *SwitchBuilder {
+	return &SwitchBuilder{stmt: &ast.SwitchStmt{Tag: tag, Body: Block()}}
+}
+
+// Case adds the clause case exprs: body.
+func (b *SwitchBuilder) Case(exprs []ast.Expr, body ...ast.Stmt) *SwitchBuilder {
+	b.stmt.Body.List = append(b.stmt.Body.List, &ast.CaseClause{List: exprs, Body: body})
+	return b
+}
+
+// Default adds the clause default: body.
+func (b *SwitchBuilder) Default(body ...ast.Stmt) *SwitchBuilder {
+	return b.Case(nil, body...)
+}
+
+// Stmt returns the switch statement.
+func (b *SwitchBuilder) Stmt() *ast.SwitchStmt {
+	return b.stmt
+}
+
+// FuncBuilder builds a func or a method declaration.
+type FuncBuilder struct {
+	decl *ast.FuncDecl
+}
+
+// BuildFunc starts the declaration of the func name.
+func BuildFunc(name string) *FuncBuilder {
+	return &FuncBuilder{decl: &ast.FuncDecl{
+		Name: ast.NewIdent(name),
+		Type: &ast.FuncType{Params: &ast.FieldList{}},
+		Body: Block(),
+	}}
+}
+
+// Doc sets the doc comment, one line per item.
+func (b *FuncBuilder) Doc(lines ...string) *FuncBuilder {
+	b.decl.Doc = docComment(lines)
+	return b
+}
+
+// Recv sets the receiver, the func becomes a method.
+func (b *FuncBuilder) Recv(name string, t ast.Expr) *FuncBuilder {
+	b.decl.Recv = &ast.FieldList{List: []*ast.Field{field(name, t)}}
+	return b
+}
+
+// Param adds a param.
+func (b *FuncBuilder) Param(name string, t ast.Expr) *FuncBuilder {
+	b.decl.Type.Params.List = append(b.decl.Type.Params.List, field(name, t))
+	return b
+}
+
+// Variadic adds the variadic param name ...t.
+func (b *FuncBuilder) Variadic(name string, t ast.Expr) *FuncBuilder {
+	return b.Param(name, &ast.Ellipsis{Elt: t})
+}
+
+// Result adds a result, name can be empty.
+func (b *FuncBuilder) Result(name string, t ast.Expr) *FuncBuilder {
+	if b.decl.Type.Results == nil {
+		b.decl.Type.Results = &ast.FieldList{}
+	}
+	b.decl.Type.Results.List = append(b.decl.Type.Results.List, field(name, t))
+	return b
+}
+
+// Body appends statements to the body.
+func (b *FuncBuilder) Body(stmts ...ast.Stmt) *FuncBuilder {
+	b.decl.Body.List = append(b.decl.Body.List, stmts...)
+	return b
+}
+
+// Decl returns the func declaration.
+func (b *FuncBuilder) Decl() *ast.FuncDecl {
+	return b.decl
+}
+
+// StructBuilder builds a struct type declaration.
+type StructBuilder struct {
+	spec *ast.TypeSpec
+	decl *ast.GenDecl
+}
+
+// BuildStruct starts the declaration of the struct type name.
+func BuildStruct(name string) *StructBuilder {
+	spec := &ast.TypeSpec{
+		Name: ast.NewIdent(name),
+		Type: &ast.StructType{Fields: &ast.FieldList{}},
+	}
+	return &StructBuilder{
+		spec: spec,
+		decl: &ast.GenDecl{Tok: token.TYPE, Specs: []ast.Spec{spec}},
+	}
+}
+
+// Doc sets the doc comment, one line per item.
+func (b *StructBuilder) Doc(lines ...string) *StructBuilder {
+	b.decl.Doc = docComment(lines)
+	return b
+}
+
+// Field adds a field, the tag can be empty.
+func (b *StructBuilder) Field(name string, t ast.Expr, tag string) *StructBuilder {
+	f := field(name, t)
+	if tag != "" {
+		f.Tag = &ast.BasicLit{Kind: token.STRING, Value: "`" + tag + "`"}
+	}
+	fields := b.spec.Type.(*ast.StructType).Fields
+	fields.List = append(fields.List, f)
+	return b
+}
+
+// Embed adds an embedded field.
+func (b *StructBuilder) Embed(t ast.Expr) *StructBuilder {
+	return b.Field("", t, "")
+}
+
+// Decl returns the type declaration.
+func (b *StructBuilder) Decl() *ast.GenDecl {
+	return b.decl
+}
+
+func field(name string, t ast.Expr) *ast.Field {
+	ret := &ast.Field{Type: t}
+	if name != "" {
+		ret.Names = []*ast.Ident{ast.NewIdent(name)}
+	}
+	return ret
+}
+
+func docComment(lines []string) *ast.CommentGroup {
+	ret := &ast.CommentGroup{}
+	for _, l := range lines {
+		for _, x := range strings.Split(l, "\n") {
+			ret.List = append(ret.List, &ast.Comment{Text: "// " + x})
+		}
+	}
+	return ret
+}
+
+var (
+	posType    = reflect.TypeOf(token.NoPos)
+	objectType = reflect.TypeOf(&ast.Object{})
+	scopeType  = reflect.TypeOf(&ast.Scope{})
+)
+
+// cloneNode returns a deep copy of the node n, detached from its file:
+// valid positions are set to 1 and objects are dropped.
+// Use it to insert a node of a loaded file into a generated ast.
+func cloneNode(n ast.Node) ast.Node {
+	return cloneValue(reflect.ValueOf(n)).Interface().(ast.Node)
+}
+
+func cloneValue(x reflect.Value) reflect.Value {
+	switch x.Kind() {
+	case reflect.Ptr:
+		if x.IsNil() || x.Type() == objectType || x.Type() == scopeType {
+			return reflect.Zero(x.Type())
+		}
+		ret := reflect.New(x.Type().Elem())
+		ret.Elem().Set(cloneValue(x.Elem()))
+		return ret
+	case reflect.Struct:
+		ret := reflect.New(x.Type()).Elem()
+		for i := 0; i < x.NumField(); i++ {
+			if ret.Field(i).CanSet() {
+				ret.Field(i).Set(cloneValue(x.Field(i)))
+			}
+		}
+		return ret
+	case reflect.Slice:
+		if x.IsNil() {
+			return x
+		}
+		ret := reflect.MakeSlice(x.Type(), x.Len(), x.Len())
+		for i := 0; i < x.Len(); i++ {
+			ret.Index(i).Set(cloneValue(x.Index(i)))
+		}
+		return ret
+	case reflect.Interface:
+		if x.IsNil() {
+			return x
+		}
+		ret := reflect.New(x.Type()).Elem()
+		ret.Set(cloneValue(x.Elem()))
+		return ret
+	}
+	if x.Type() == posType && x.Int() != 0 {
+		return reflect.ValueOf(token.Pos(1))
+	}
+	return x
+}
@@ -0,0 +1,77 @@
+package astutil
+
+import (
+	"go/ast"
+	"go/token"
+	"testing"
+)
+
+func TestBuildFunc(t *testing.T) {
+	f := BuildFunc("Do").
+		Doc("Do does.").
+		Recv("t", Star(Ident("T"))).
+		Param("a", Type("map[string]*pkg.T")).
+		Variadic("b", Ident("int")).
+		Result("", Ident("error")).
+		Body(
+			Define([]string{"x"}, TypeToStructInit(Type("*pkg.T"))),
+			BuildIf(Binary(Call(Ident("len"), Ident("b")), token.EQL, Int(0)),
+				Return(Nil()),
+			).ElseIf(Not(Ident("ok")),
+				ExprStmt(CallVariadic(Sel(Ident("t"), "next", "Do"), Ident("a"), Ident("b"))),
+			).Else(
+				Set(Index(Ident("a"), Str("k")), Ident("x")),
+			).Stmt(),
+			Range("k", "", Ident("a"),
+				BuildSwitch(Ident("k")).
+					Case([]ast.Expr{Str("a")}, Assign(token.ADD_ASSIGN, []ast.Expr{Ident("i")}, Int(1))).
+					Default().
+					Stmt(),
+			),
+			For(nil, nil, nil),
+			Return(Composite(Ident("E"), KeyValue(Ident("K"), Addr(Ident("x"))))),
+		).Decl()
+	want := `// Do does.
+func (t *T) Do(a map[string]*pkg.T, b ...int) error {
+	x := &pkg.T{}
+	if len(b) == 0 {
+		return nil
+	} else if !ok {
+		t.next.Do(a, b...)
+	} else {
+		a["k"] = x
+	}
+	for k := range a {
+		switch k {
+		case "a":
+			i += 1
+		default:
+		}
+	}
+	for {
+	}
+	return E{K: &x}
+}`
+	if got := Print(f); want != got {
+		t.Errorf("want=\n%v\ngot=\n%v", want, got)
+	}
+}
+
+func TestBuildStruct(t *testing.T) {
+	s := BuildStruct("T").
+		Doc("T is a type.").
+		Embed(Star(Type("sync.Mutex"))).
+		Field("Name", Ident("string"), `json:"name"`).
+		Decl()
+	want := "// T is a type.\ntype T struct {\n\t*sync.Mutex\n\tName string `json:\"name\"`\n}"
+	if got := Print(s); want != got {
+		t.Errorf("want=\n%v\ngot=\n%v", want, got)
+	}
+}
+
+func TestBuildInvalidType(t *testing.T) {
+	f := BuildFunc("Do").Param("a", Type("map[")).Decl()
+	if got := Print(f); got != "" {
+		t.Errorf("want empty string for an invalid type got %q", got)
+	}
+}
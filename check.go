@@ -0,0 +1,115 @@
+package astutil
+
+import (
+	"bytes"
+	"go/ast"
+	"go/format"
+	"go/importer"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/loader"
+)
+
+// CheckGenerated type checks the generated go files, their sources by name,
+// together with the files of the package pkg of prog,
+// a package file having the base name of a generated file is left out.
+// It runs in memory, with its own file set, prog is not modified.
+// Imports are resolved with the packages of prog,
+// the other packages are type checked from their go sources,
+// which is slow, such an importer is created at most once per call.
+// It returns a scanner.ErrorList of the parse or type errors,
+// their positions are in the generated files.
+func CheckGenerated(prog *loader.Program, pkg *loader.PackageInfo, files map[string][]byte) error {
+	var names []string
+	for name := range files {
+		if strings.HasSuffix(name, ".go") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	// a generated file replaces the package file of the same name.
+	generated := map[string]bool{}
+	for _, name := range names {
+		generated[filepath.Base(name)] = true
+	}
+	fset := token.NewFileSet()
+	var all []*ast.File
+	for _, f := range pkg.Files {
+		name := prog.Fset.File(f.Pos()).Name()
+		if generated[filepath.Base(name)] {
+			continue
+		}
+		// the package files are copied into fset.
+		var b bytes.Buffer
+		if err := format.Node(&b, prog.Fset, f); err != nil {
+			return err
+		}
+		x, err := parser.ParseFile(fset, name, b.Bytes(), parser.ParseComments)
+		if err != nil {
+			return err
+		}
+		all = append(all, x)
+	}
+	for _, name := range names {
+		f, err := parser.ParseFile(fset, name, files[name], parser.ParseComments)
+		if err != nil {
+			return err
+		}
+		all = append(all, f)
+	}
+
+	var errs scanner.ErrorList
+	conf := types.Config{
+		Importer: newProgImporter(prog, fset),
+		Error: func(err error) {
+			if e, ok := err.(types.Error); ok {
+				errs.Add(e.Fset.Position(e.Pos), e.Msg)
+			}
+		},
+	}
+	_, err := conf.Check(pkg.Pkg.Path(), fset, all, nil)
+	if len(errs) == 0 {
+		return err
+	}
+	errs.Sort()
+	return errs
+}
+
+// progImporter imports the packages loaded by a program,
+// other packages are imported from their sources by the fallback importer,
+// it is created on first use.
+type progImporter struct {
+	pkgs     map[string]*types.Package
+	fset     *token.FileSet
+	fallback types.ImporterFrom
+}
+
+func newProgImporter(prog *loader.Program, fset *token.FileSet) *progImporter {
+	ret := &progImporter{pkgs: map[string]*types.Package{}, fset: fset}
+	for p := range prog.AllPackages {
+		if p.Complete() {
+			ret.pkgs[p.Path()] = p
+		}
+	}
+	return ret
+}
+
+func (i *progImporter) Import(path string) (*types.Package, error) {
+	return i.ImportFrom(path, "", 0)
+}
+
+func (i *progImporter) ImportFrom(path, dir string, mode types.ImportMode) (*types.Package, error) {
+	if p, ok := i.pkgs[path]; ok {
+		return p, nil
+	}
+	if i.fallback == nil {
+		i.fallback = importer.ForCompiler(i.fset, "source", nil).(types.ImporterFrom)
+	}
+	return i.fallback.ImportFrom(path, dir, mode)
+}
@@ -0,0 +1,100 @@
+package astutil
+
+import (
+	"go/scanner"
+	"testing"
+)
+
+func TestCheckGenerated(t *testing.T) {
+	prog := getProgramFromFiles(map[string]string{
+		"t.go": `package thepackagename
+
+import "io"
+
+type T struct {
+	W io.Writer
+}
+`,
+	})
+	pkg := prog.Package("thepackagename")
+	base := prog.Fset.Base()
+	err := CheckGenerated(prog, pkg, map[string][]byte{
+		"t_gen.go": []byte(`package thepackagename
+
+import (
+	"io"
+	"strings"
+)
+
+// NewT creates a T.
+func NewT(w io.Writer) *T {
+	return &T{W: w}
+}
+
+// Upper writes s in upper case.
+func (t *T) Upper(s string) error {
+	_, err := t.W.Write([]byte(strings.ToUpper(s)))
+	return err
+}
+`),
+		"README.md": []byte("not go"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := prog.Fset.Base(); base != got {
+		t.Errorf("the file set of the program must not change, want base %v got %v", base, got)
+	}
+
+	err = CheckGenerated(prog, pkg, map[string][]byte{
+		"t_gen.go": []byte(`package thepackagename
+
+func NewT() *T {
+	return &T{Name: "t"}
+}
+
+func (t T) Len() int {
+	return t.W.Len()
+}
+`),
+	})
+	list, ok := err.(scanner.ErrorList)
+	if !ok {
+		t.Fatalf("want a scanner.ErrorList got=%#v", err)
+	}
+	if len(list) != 2 {
+		t.Fatalf("want=2 errors got=%v", list)
+	}
+	for i, line := range []int{4, 8} {
+		if pos := list[i].Pos; pos.Filename != "t_gen.go" || pos.Line != line {
+			t.Errorf("want=t_gen.go:%v got=%v", line, pos)
+		}
+	}
+}
+
+func TestCheckGeneratedSyntaxError(t *testing.T) {
+	prog := getProgramFromFiles(map[string]string{"t.go": "package thepackagename\n"})
+	err := CheckGenerated(prog, prog.Package("thepackagename"), map[string][]byte{
+		"t_gen.go": []byte("package thepackagename\n\nfunc F( {}\n"),
+	})
+	list, ok := err.(scanner.ErrorList)
+	if !ok || len(list) == 0 {
+		t.Fatalf("want a scanner.ErrorList got=%#v", err)
+	}
+	if pos := list[0].Pos; pos.Filename != "t_gen.go" || pos.Line != 3 {
+		t.Errorf("want=t_gen.go:3 got=%v", pos)
+	}
+}
+
+func TestCheckGeneratedReplace(t *testing.T) {
+	prog := getProgramFromFiles(map[string]string{
+		"t.go":     "package thepackagename\n\ntype T struct{}\n",
+		"t_gen.go": "package thepackagename\n\nfunc NewT() *T { return &T{} }\n",
+	})
+	err := CheckGenerated(prog, prog.Package("thepackagename"), map[string][]byte{
+		"out/t_gen.go": []byte("package thepackagename\n\nfunc NewT() T { return T{} }\n"),
+	})
+	if err != nil {
+		t.Errorf("the generated file must replace the package file: %v", err)
+	}
+}
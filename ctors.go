@@ -0,0 +1,80 @@
+package astutil
+
+import (
+	"go/ast"
+	"path"
+	"strings"
+
+	"golang.org/x/tools/go/loader"
+)
+
+// DefaultCtorPatterns are the naming patterns used by FindConstructors.
+var DefaultCtorPatterns = []string{
+	"New$T*",
+	"MustNew$T*",
+	"New",
+}
+
+// FindConstructors searches given package for every ctors of given struct list.
+// A ctor is a func returning T, *T, (T, error) or (*T, error),
+// whose name matches one of the given patterns.
+// A pattern is a path.Match pattern where $T is replaced by the type name,
+// DefaultCtorPatterns is used when no patterns are given.
+func FindConstructors(p *loader.PackageInfo, aboutTypes []string, patterns ...string) map[string][]*ast.FuncDecl {
+	if len(patterns) == 0 {
+		patterns = DefaultCtorPatterns
+	}
+	foundCtors := map[string][]*ast.FuncDecl{}
+	for _, file := range p.Files {
+		for _, decl := range file.Decls {
+			x, ok := decl.(*ast.FuncDecl)
+			if !ok || x.Recv != nil {
+				continue
+			}
+			for _, t := range aboutTypes {
+				if IsCtorOf(x, t) && matchCtorPatterns(MethodName(x), t, patterns) {
+					foundCtors[t] = append(foundCtors[t], x)
+				}
+			}
+		}
+	}
+	return foundCtors
+}
+
+// IsCtorOf returns true if m returns t, *t, (t, error) or (*t, error).
+func IsCtorOf(m *ast.FuncDecl, t string) bool {
+	if m.Type.Results == nil {
+		return false
+	}
+	var results []ast.Expr
+	for _, r := range m.Type.Results.List {
+		for range r.Names {
+			results = append(results, r.Type)
+		}
+		if len(r.Names) == 0 {
+			results = append(results, r.Type)
+		}
+	}
+	if len(results) < 1 || len(results) > 2 {
+		return false
+	}
+	if GetUnpointedType(ToString(results[0])) != t {
+		return false
+	}
+	if len(results) == 2 {
+		if x, ok := results[1].(*ast.Ident); !ok || x.Name != "error" {
+			return false
+		}
+	}
+	return true
+}
+
+func matchCtorPatterns(name, t string, patterns []string) bool {
+	for _, pattern := range patterns {
+		pattern = strings.Replace(pattern, "$T", t, -1)
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,58 @@
+package astutil
+
+import "testing"
+
+func TestFindConstructors(t *testing.T) {
+	prog := getProgramFromString(`
+type T struct{}
+type TT struct{}
+type Option func(*T)
+
+func NewT() *T { return nil }
+func NewTWithName(name string) (*T, error) { return nil, nil }
+func MustNewT(opts ...Option) T { return T{} }
+func New() *T { return nil }
+func NewTT() *TT { return nil }
+func NewTBad() (*T, string) { return nil, "" }
+func MakeT() *T { return nil }
+`)
+	pkg := prog.Package("thepackagename")
+	got := FindConstructors(pkg, []string{"T", "TT"})
+	want := []string{"NewT", "NewTWithName", "MustNewT", "New"}
+	if len(want) != len(got["T"]) {
+		t.Fatalf("want %v ctors got %v", len(want), len(got["T"]))
+	}
+	for i, w := range want {
+		if g := MethodName(got["T"][i]); w != g {
+			t.Errorf("want %v got %v", w, g)
+		}
+	}
+	if l := len(got["TT"]); l != 1 {
+		t.Errorf("want %v ctors got %v", 1, l)
+	}
+
+	got = FindConstructors(pkg, []string{"T"}, "Make$T")
+	if l := len(got["T"]); l != 1 {
+		t.Errorf("want %v ctors got %v", 1, l)
+	}
+}
+
+func TestIsCtorOf(t *testing.T) {
+	tests := []struct {
+		src  string
+		want bool
+	}{
+		{`func f() T {}`, true},
+		{`func f() (*T, error) {}`, true},
+		{`func f() (t *T, err error) {}`, true},
+		{`func f() (*T, *T) {}`, false},
+		{`func f() {}`, false},
+		{`func f() *Y {}`, false},
+	}
+	for _, test := range tests {
+		got := IsCtorOf(getFuncDecl(test.src), "T")
+		if test.want != got {
+			t.Errorf("%v: want %v got %v", test.src, test.want, got)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package astutil
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/types"
+	"strings"
+	"text/template"
+
+	"golang.org/x/tools/go/loader"
+)
+
+// DecoratorOptions configures GenerateDecorator.
+type DecoratorOptions struct {
+	// Name of the decorator type, it defaults to the interface name suffixed with Decorator.
+	Name string
+	// Fields are additional fields of the decorator struct, such as "Logger *log.Logger".
+	Fields []string
+	// Imports are additional import paths required by the fields and the templates.
+	Imports []string
+	// Before and After are text/template of the statements
+	// inserted before and after the delegated call of each method.
+	// The template data is a DecoratorMethod.
+	// Comments can not be generated, a template producing a comment fails.
+	Before string
+	After  string
+}
+
+// DecoratorMethod is the data of the Before and After templates of a decorator.
+type DecoratorMethod struct {
+	// Decorator is the name of the decorator type.
+	Decorator string
+	// Recv is the name of the receiver variable.
+	Recv string
+	// Next is the name of the field holding the decorated value.
+	Next string
+	// Name of the decorated method.
+	Name      string
+	Signature *Signature
+	// Results are the names of the variables holding the results of the call,
+	// it is empty for the Before template.
+	Results []string
+}
+
+// GenerateDecorator generates the source of a file declaring
+// a decorator of the interface iface.
+// The decorator implements iface, each method delegates the call
+// to the Next field surrounded by the Before and After statements.
+// The field is named Next1, Next2... when iface has a Next method.
+func GenerateDecorator(p *loader.PackageInfo, iface string, o DecoratorOptions) ([]byte, error) {
+	t := GetType(p, iface)
+	if t == nil {
+		return nil, fmt.Errorf("interface %q not found", iface)
+	}
+	it, ok := t.Underlying().(*types.Interface)
+	if !ok {
+		return nil, fmt.Errorf("type %q is not an interface", iface)
+	}
+	if o.Name == "" {
+		o.Name = iface[strings.LastIndex(iface, ".")+1:] + "Decorator"
+	}
+	before, err := template.New("before").Parse(o.Before)
+	if err != nil {
+		return nil, err
+	}
+	after, err := template.New("after").Parse(o.After)
+	if err != nil {
+		return nil, err
+	}
+
+	imports := NewImports()
+	for _, i := range o.Imports {
+		if err := imports.Add("", i); err != nil {
+			return nil, err
+		}
+	}
+	q := imports.Qualifier(p.Pkg)
+	next := types.TypeString(t, q)
+	fields := NewNamer()
+	for i := 0; i < it.NumMethods(); i++ {
+		fields.Reserve(it.Method(i).Name())
+	}
+	for _, f := range o.Fields {
+		if x := strings.Fields(f); len(x) > 0 {
+			fields.Reserve(x[0])
+		}
+	}
+	nextField := fields.Name("Next")
+
+	var body bytes.Buffer
+	for i := 0; i < it.NumMethods(); i++ {
+		m := it.Method(i)
+		s := methodSignature(m, q)
+		namer := NewNamer()
+		for _, x := range s.all() {
+			namer.Reserve(x.Name)
+		}
+		data := &DecoratorMethod{
+			Decorator: o.Name,
+			Recv:      namer.Name("d"),
+			Next:      nextField,
+			Name:      m.Name(),
+			Signature: s,
+		}
+		var tplErr error
+		hook := func(tpl *template.Template) WrapperHook {
+			return func(s *Signature, results []string, n *Namer) []ast.Stmt {
+				data.Results = results
+				var b bytes.Buffer
+				if err := tpl.Execute(&b, data); err != nil {
+					tplErr = err
+					return nil
+				}
+				ret, comments, err := parseStmts(b.String(), parser.ParseComments)
+				if err == nil && len(comments) > 0 {
+					err = fmt.Errorf("comments are not supported: %v", comments[0].List[0].Text)
+				}
+				if err != nil && tplErr == nil {
+					tplErr = fmt.Errorf("%v of %v: %v", tpl.Name(), m.Name(), err)
+				}
+				return ret
+			}
+		}
+		w := GenerateSignatureWrapper(m.Name(), s, WrapperOptions{
+			Recv: &ast.Field{
+				Names: []*ast.Ident{ast.NewIdent(data.Recv)},
+				Type:  &ast.StarExpr{X: ast.NewIdent(o.Name)},
+			},
+			Target: &ast.SelectorExpr{X: ast.NewIdent(data.Recv), Sel: ast.NewIdent(nextField)},
+			Before: hook(before),
+			After:  hook(after),
+		})
+		if tplErr != nil {
+			return nil, tplErr
+		}
+		fmt.Fprintf(&body, "\n// %v decorates %v.%v.\n", m.Name(), next, m.Name())
+		body.WriteString(Print(w) + "\n")
+	}
+	if err := imports.Err(); err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "package %v\n\n", p.Pkg.Name())
+	b.WriteString(imports.String())
+	fmt.Fprintf(&b, "\n// %v is a decorator of %v.\n", o.Name, next)
+	fmt.Fprintf(&b, "type %v struct {\n", o.Name)
+	fmt.Fprintf(&b, "%v %v\n", nextField, next)
+	for _, f := range o.Fields {
+		b.WriteString(f + "\n")
+	}
+	b.WriteString("}\n")
+	b.Write(body.Bytes())
+	return format.Source(b.Bytes())
+}
@@ -0,0 +1,107 @@
+package astutil
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateDecorator(t *testing.T) {
+	src := `
+type I interface {
+	Do(string, ...int) (int, error)
+	Close()
+}
+`
+	prog := getProgramFromString(src)
+	pkg := prog.Package("thepackagename")
+	got, err := GenerateDecorator(pkg, "I", DecoratorOptions{
+		Fields:  []string{"Logger *log.Logger"},
+		Imports: []string{"log"},
+		Before:  `{{.Recv}}.Logger.Println("before {{.Name}}")`,
+		After:   `{{if .Results}}{{.Recv}}.Logger.Println("after {{.Name}}", {{index .Results 0}}){{end}}`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `package thepackagename
+
+import (
+	"log"
+)
+
+// IDecorator is a decorator of I.
+type IDecorator struct {
+	Next   I
+	Logger *log.Logger
+}
+
+// Close decorates I.Close.
+func (d *IDecorator) Close() {
+	d.Logger.Println("before Close")
+	d.Next.Close()
+}
+
+// Do decorates I.Do.
+func (d *IDecorator) Do(arg0 string, arg1 ...int) (int, error) {
+	d.Logger.Println("before Do")
+	retVar0, retVar1 := d.Next.Do(arg0, arg1...)
+	d.Logger.Println("after Do", retVar0)
+	return retVar0, retVar1
+}
+`
+	if want != string(got) {
+		t.Errorf("want=\n%v\ngot=\n%v", want, string(got))
+	}
+
+	assertCompiles(t, prog, got)
+}
+
+func TestGenerateDecoratorCompiles(t *testing.T) {
+	prog := getProgramFromString(`
+import "io"
+
+type I interface {
+	io.Closer
+	Next() (d int)
+}
+
+var _ io.Reader
+`)
+	pkg := prog.Package("thepackagename")
+	got, err := GenerateDecorator(pkg, "I", DecoratorOptions{
+		Before: `_ = {{.Recv}}.{{.Next}}`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"Next1 I\n",
+		"func (d1 *IDecorator) Next() (d int) {",
+	} {
+		if !strings.Contains(string(got), want) {
+			t.Errorf("generated decorator does not contain %q\n%s", want, got)
+		}
+	}
+	if strings.Contains(string(got), `"io"`) {
+		t.Errorf("the decorator must not import io\n%s", got)
+	}
+	assertCompiles(t, prog, got)
+}
+
+func TestGenerateDecoratorInvalidTemplate(t *testing.T) {
+	prog := getProgramFromString(`type I interface{ Do() }`)
+	pkg := prog.Package("thepackagename")
+	_, err := GenerateDecorator(pkg, "I", DecoratorOptions{Before: `if {`})
+	if err == nil {
+		t.Errorf("want an error")
+	}
+}
+
+func TestGenerateDecoratorComment(t *testing.T) {
+	prog := getProgramFromString(`type I interface{ Do() }`)
+	pkg := prog.Package("thepackagename")
+	_, err := GenerateDecorator(pkg, "I", DecoratorOptions{Before: "// before\nprintln()"})
+	if err == nil || !strings.Contains(err.Error(), "comments are not supported") {
+		t.Errorf("want a comment error got %v", err)
+	}
+}
@@ -0,0 +1,228 @@
+package astutil
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// UnifiedDiff returns the unified diff of a to b, it is empty when they are equal.
+func UnifiedDiff(fromName, toName string, a, b []byte) string {
+	if bytes.Equal(a, b) {
+		return ""
+	}
+	ops := diffLines(splitLines(a), splitLines(b))
+
+	var ret bytes.Buffer
+	fmt.Fprintf(&ret, "--- %v\n+++ %v\n", fromName, toName)
+	const context = 3
+	for i := 0; i < len(ops); {
+		if ops[i].kind == ' ' {
+			i++
+			continue
+		}
+		// a hunk starts context lines before the change,
+		// and ends when more than 2*context lines are unchanged.
+		start := i - context
+		if start < 0 {
+			start = 0
+		}
+		end := i
+		for end < len(ops) {
+			if ops[end].kind != ' ' {
+				end++
+				continue
+			}
+			n := 0
+			for end+n < len(ops) && ops[end+n].kind == ' ' {
+				n++
+			}
+			if end+n == len(ops) || n > 2*context {
+				if n > context {
+					n = context
+				}
+				end += n
+				break
+			}
+			end += n
+		}
+		var aLen, bLen int
+		for _, op := range ops[start:end] {
+			if op.kind != '+' {
+				aLen++
+			}
+			if op.kind != '-' {
+				bLen++
+			}
+		}
+		fmt.Fprintf(&ret, "@@ -%v +%v @@\n",
+			hunkRange(ops[start].a, aLen), hunkRange(ops[start].b, bLen))
+		for _, op := range ops[start:end] {
+			fmt.Fprintf(&ret, "%c%v\n", op.kind, op.line)
+		}
+		i = end
+	}
+	return ret.String()
+}
+
+type diffOp struct {
+	kind byte // ' ', '-' or '+'
+	line string
+	// a and b are the line indexes before the op is applied.
+	a, b int
+}
+
+// diffLines computes the edit script of a to b with the linear space variant
+// of the Myers' diff algorithm, so that large files can be compared.
+func diffLines(a, b []string) []diffOp {
+	d := &differ{a: a, b: b}
+	d.diff(0, len(a), 0, len(b))
+
+	// within a change, the deletions come first.
+	for i := 0; i < len(d.ops); {
+		if d.ops[i].kind == ' ' {
+			i++
+			continue
+		}
+		j := i
+		for j < len(d.ops) && d.ops[j].kind != ' ' {
+			j++
+		}
+		sort.SliceStable(d.ops[i:j], func(x, y int) bool {
+			return d.ops[i+x].kind == '-' && d.ops[i+y].kind == '+'
+		})
+		i = j
+	}
+	i, j := 0, 0
+	for k, op := range d.ops {
+		d.ops[k].a, d.ops[k].b = i, j
+		if op.kind != '+' {
+			i++
+		}
+		if op.kind != '-' {
+			j++
+		}
+	}
+	return d.ops
+}
+
+// differ holds the state of diffLines.
+type differ struct {
+	a, b []string
+	ops  []diffOp
+}
+
+// diff appends the edit script of a[a0:a1] to b[b0:b1].
+func (d *differ) diff(a0, a1, b0, b1 int) {
+	for a0 < a1 && b0 < b1 && d.a[a0] == d.b[b0] {
+		d.ops = append(d.ops, diffOp{kind: ' ', line: d.a[a0]})
+		a0++
+		b0++
+	}
+	n := 0
+	for a0 < a1-n && b0 < b1-n && d.a[a1-n-1] == d.b[b1-n-1] {
+		n++
+	}
+	switch {
+	case a0 == a1-n:
+		for _, l := range d.b[b0 : b1-n] {
+			d.ops = append(d.ops, diffOp{kind: '+', line: l})
+		}
+	case b0 == b1-n:
+		for _, l := range d.a[a0 : a1-n] {
+			d.ops = append(d.ops, diffOp{kind: '-', line: l})
+		}
+	default:
+		// both ends differ, at least 2 edits are required,
+		// the sub problems are strictly smaller.
+		x, y, u, v := d.middleSnake(a0, a1-n, b0, b1-n)
+		d.diff(a0, x, b0, y)
+		for _, l := range d.a[x:u] {
+			d.ops = append(d.ops, diffOp{kind: ' ', line: l})
+		}
+		d.diff(u, a1-n, v, b1-n)
+	}
+	for _, l := range d.a[a1-n : a1] {
+		d.ops = append(d.ops, diffOp{kind: ' ', line: l})
+	}
+}
+
+// middleSnake finds the middle snake (x,y)-(u,v) of an optimal edit script
+// of a[a0:a1] to b[b0:b1] by searching forward and backward at once.
+func (d *differ) middleSnake(a0, a1, b0, b1 int) (x, y, u, v int) {
+	n, m := a1-a0, b1-b0
+	max := (n + m + 1) / 2
+	off := max + 1
+	// vf and vb hold the furthest x reached on each diagonal,
+	// vb in the coordinates of the reversed sequences.
+	vf := make([]int, 2*off+1)
+	vb := make([]int, 2*off+1)
+	delta := n - m
+	odd := delta%2 != 0
+	for e := 0; e <= max; e++ {
+		for k := -e; k <= e; k += 2 {
+			var x int
+			if k == -e || (k != e && vf[off+k-1] < vf[off+k+1]) {
+				x = vf[off+k+1]
+			} else {
+				x = vf[off+k-1] + 1
+			}
+			y := x - k
+			sx, sy := x, y
+			for x < n && y < m && d.a[a0+x] == d.b[b0+y] {
+				x++
+				y++
+			}
+			vf[off+k] = x
+			if kb := delta - k; odd && kb >= -(e-1) && kb <= e-1 && x+vb[off+kb] >= n {
+				return a0 + sx, b0 + sy, a0 + x, b0 + y
+			}
+		}
+		for k := -e; k <= e; k += 2 {
+			var x int
+			if k == -e || (k != e && vb[off+k-1] < vb[off+k+1]) {
+				x = vb[off+k+1]
+			} else {
+				x = vb[off+k-1] + 1
+			}
+			y := x - k
+			sx, sy := x, y
+			for x < n && y < m && d.a[a1-1-x] == d.b[b1-1-y] {
+				x++
+				y++
+			}
+			vb[off+k] = x
+			if kf := delta - k; !odd && kf >= -e && kf <= e && x+vf[off+kf] >= n {
+				return a1 - x, b1 - y, a1 - sx, b1 - sy
+			}
+		}
+	}
+	panic("astutil: no middle snake found")
+}
+
+func hunkRange(start, n int) string {
+	if n == 0 {
+		return fmt.Sprintf("%v,0", start)
+	}
+	if n == 1 {
+		return fmt.Sprintf("%v", start+1)
+	}
+	return fmt.Sprintf("%v,%v", start+1, n)
+}
+
+// noNewline marks the last line of a text not ending with a new line,
+// such a line differs from the same line ending with a new line.
+const noNewline = "\n\\ No newline at end of file"
+
+// splitLines returns the lines of s, without their new line.
+func splitLines(s []byte) []string {
+	if len(s) == 0 {
+		return nil
+	}
+	ret := strings.Split(strings.TrimSuffix(string(s), "\n"), "\n")
+	if s[len(s)-1] != '\n' {
+		ret[len(ret)-1] += noNewline
+	}
+	return ret
+}
@@ -0,0 +1,143 @@
+package astutil
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	a := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n"
+	b := "1\n2\n3\n4\nfive\n6\n7\n8\n9\n10\n11\n12\n13\n"
+	want := `--- a
++++ b
+@@ -2,7 +2,7 @@
+ 2
+ 3
+ 4
+-5
++five
+ 6
+ 7
+ 8
+@@ -10,3 +10,4 @@
+ 10
+ 11
+ 12
++13
+`
+	if got := UnifiedDiff("a", "b", []byte(a), []byte(b)); want != got {
+		t.Errorf("want=\n%v\ngot=\n%v", want, got)
+	}
+	if got := UnifiedDiff("a", "b", []byte(a), []byte(a)); got != "" {
+		t.Errorf("want empty got %q", got)
+	}
+	want = `--- a
++++ b
+@@ -0,0 +1,2 @@
++1
++2
+`
+	if got := UnifiedDiff("a", "b", nil, []byte("1\n2\n")); want != got {
+		t.Errorf("want=\n%v\ngot=\n%v", want, got)
+	}
+	want = `--- a
++++ b
+@@ -1,2 +1,2 @@
+ 1
+-2
+\ No newline at end of file
++2
+`
+	if got := UnifiedDiff("a", "b", []byte("1\n2"), []byte("1\n2\n")); want != got {
+		t.Errorf("want=\n%v\ngot=\n%v", want, got)
+	}
+	want = `--- a
++++ b
+@@ -1 +1 @@
+-1
++2
+\ No newline at end of file
+`
+	if got := UnifiedDiff("a", "b", []byte("1\n"), []byte("2")); want != got {
+		t.Errorf("want=\n%v\ngot=\n%v", want, got)
+	}
+}
+
+func TestDiffLines(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	gen := func() []string {
+		var ret []string
+		for i := r.Intn(20); i > 0; i-- {
+			ret = append(ret, string(rune('a'+r.Intn(4))))
+		}
+		return ret
+	}
+	for i := 0; i < 2000; i++ {
+		a, b := gen(), gen()
+		ops := diffLines(a, b)
+		var gotA, gotB []string
+		edits := 0
+		for k, op := range ops {
+			if op.a != len(gotA) || op.b != len(gotB) {
+				t.Fatalf("%q %q: op %v has wrong indexes %v %v", a, b, k, op.a, op.b)
+			}
+			if op.kind != '+' {
+				gotA = append(gotA, op.line)
+			}
+			if op.kind != '-' {
+				gotB = append(gotB, op.line)
+			}
+			if op.kind != ' ' {
+				edits++
+			}
+		}
+		if strings.Join(a, "") != strings.Join(gotA, "") || strings.Join(b, "") != strings.Join(gotB, "") {
+			t.Fatalf("%q %q: wrong edit script %v", a, b, ops)
+		}
+		if want := len(a) + len(b) - 2*lcsLen(a, b); edits != want {
+			t.Fatalf("%q %q: want %v edits got %v", a, b, want, edits)
+		}
+	}
+}
+
+func TestUnifiedDiffLarge(t *testing.T) {
+	var a, b bytes.Buffer
+	for i := 0; i < 20000; i++ {
+		fmt.Fprintf(&a, "line %v\n", i)
+		if i%5000 == 0 {
+			fmt.Fprintf(&b, "changed %v\n", i)
+			continue
+		}
+		fmt.Fprintf(&b, "line %v\n", i)
+	}
+	got := UnifiedDiff("a", "b", a.Bytes(), b.Bytes())
+	if n := strings.Count(got, "\n-line"); n != 4 {
+		t.Errorf("want 4 deletions got %v", n)
+	}
+	if n := strings.Count(got, "\n+changed"); n != 4 {
+		t.Errorf("want 4 insertions got %v", n)
+	}
+}
+
+// lcsLen returns the length of the longest common subsequence of a and b.
+func lcsLen(a, b []string) int {
+	l := make([][]int, len(a)+1)
+	for i := range l {
+		l[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				l[i][j] = l[i+1][j+1] + 1
+			} else if l[i+1][j] > l[i][j+1] {
+				l[i][j] = l[i+1][j]
+			} else {
+				l[i][j] = l[i][j+1]
+			}
+		}
+	}
+	return l[0][0]
+}
@@ -0,0 +1,135 @@
+package astutil
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+)
+
+// Func is a common view over the func like nodes.
+// It is an ast.Node, so that a Func of a go/types value
+// can be given to the helpers accepting a func like node, such as the Method* helpers.
+type Func struct {
+	// Name is empty for a func type or a func literal.
+	Name string
+	// Recv is nil for a func.
+	Recv *ast.FieldList
+	Type *ast.FuncType
+	Doc  *ast.CommentGroup
+	// Signature is the type checked signature, it is nil unless
+	// the func was created from a *types.Func or a *types.Signature.
+	Signature *types.Signature
+}
+
+// Pos returns the position of the func type.
+func (f *Func) Pos() token.Pos { return f.Type.Pos() }
+
+// End returns the end position of the func type.
+func (f *Func) End() token.Pos { return f.Type.End() }
+
+// GetFunc returns the Func of x.
+// x can be an *ast.FuncDecl, an *ast.FuncType, an *ast.FuncLit,
+// an *ast.Field of an interface method or of a func typed struct field,
+// a *types.Func, a *types.Signature or a *Func.
+// It returns an error for any other value, such as the field of an embedded interface.
+// Types of a *types.Func or a *types.Signature are qualified by their package name,
+// see GetFuncQualified.
+func GetFunc(x interface{}) (*Func, error) {
+	return GetFuncQualified(x, func(p *types.Package) string {
+		return p.Name()
+	})
+}
+
+// GetFuncQualified is GetFunc, types of a *types.Func or a *types.Signature are qualified with q,
+// such as types.RelativeTo(pkg) to leave the types of the package pkg unqualified.
+func GetFuncQualified(x interface{}, q types.Qualifier) (*Func, error) {
+	switch m := x.(type) {
+	case *Func:
+		return m, nil
+	case *ast.FuncDecl:
+		return &Func{Name: m.Name.Name, Recv: m.Recv, Type: m.Type, Doc: m.Doc}, nil
+	case *ast.FuncType:
+		return &Func{Type: m}, nil
+	case *ast.FuncLit:
+		return &Func{Type: m.Type}, nil
+	case *ast.Field:
+		t, ok := m.Type.(*ast.FuncType)
+		if !ok {
+			return nil, fmt.Errorf("astutil: the field of type %v is not a func", Print(m.Type))
+		}
+		ret := &Func{Type: t, Doc: m.Doc}
+		if len(m.Names) > 0 {
+			ret.Name = m.Names[0].Name
+		}
+		return ret, nil
+	case *types.Func:
+		ret := signatureFunc(m.Type().(*types.Signature), q)
+		ret.Name = m.Name()
+		return ret, nil
+	case *types.Signature:
+		return signatureFunc(m, q), nil
+	}
+	return nil, fmt.Errorf("astutil: unsupported func like value %T", x)
+}
+
+// signatureFunc returns the Func of the signature s, types are qualified with q.
+func signatureFunc(s *types.Signature, q types.Qualifier) *Func {
+	ret := &Func{
+		Type: &ast.FuncType{
+			Params:  typesFieldList(s.Params(), s.Variadic(), q),
+			Results: typesFieldList(s.Results(), false, q),
+		},
+		Signature: s,
+	}
+	if s.Recv() != nil {
+		ret.Recv = typesFieldList(types.NewTuple(s.Recv()), false, q)
+	}
+	return ret
+}
+
+// methodSignature returns the Signature of the method m without its receiver,
+// types are qualified with q.
+func methodSignature(m *types.Func, q types.Qualifier) *Signature {
+	s := m.Type().(*types.Signature)
+	return GetSignature(signatureFunc(types.NewSignatureType(nil, nil, nil, s.Params(), s.Results(), s.Variadic()), q))
+}
+
+// funcOf returns the Func of the node m for the helpers not returning an error,
+// the Func of an unsupported node has no name, no params and no results.
+func funcOf(m ast.Node) *Func {
+	ret, err := GetFunc(m)
+	if err != nil {
+		return &Func{Type: &ast.FuncType{Params: &ast.FieldList{}}}
+	}
+	return ret
+}
+
+func typesFieldList(t *types.Tuple, variadic bool, q types.Qualifier) *ast.FieldList {
+	ret := &ast.FieldList{}
+	for i := 0; i < t.Len(); i++ {
+		v := t.At(i)
+		f := &ast.Field{}
+		if v.Name() != "" {
+			f.Names = []*ast.Ident{ast.NewIdent(v.Name())}
+		}
+		if variadic && i == t.Len()-1 {
+			f.Type = &ast.Ellipsis{Elt: typeExpr(v.Type().(*types.Slice).Elem(), q)}
+		} else {
+			f.Type = typeExpr(v.Type(), q)
+		}
+		ret.List = append(ret.List, f)
+	}
+	return ret
+}
+
+// typeExpr returns the type expression of t, qualified with q.
+func typeExpr(t types.Type, q types.Qualifier) ast.Expr {
+	s := types.TypeString(t, q)
+	ret, err := parser.ParseExpr(s)
+	if err != nil {
+		return ast.NewIdent(s)
+	}
+	return ret
+}
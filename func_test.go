@@ -0,0 +1,99 @@
+package astutil
+
+import (
+	"go/ast"
+	"go/types"
+	"testing"
+)
+
+func TestGetFunc(t *testing.T) {
+	y := getStructDecl(`type T struct{
+	F func(a string) error
+}`)
+	field := y.Type.(*ast.StructType).Fields.List[0]
+	want := "a string"
+	if got := MethodParams(field); want != got {
+		t.Errorf("want %q got %q", want, got)
+	}
+	if got := MethodName(field); got != "F" {
+		t.Errorf("want %q got %q", "F", got)
+	}
+	if !MethodReturnError(field.Type) {
+		t.Errorf("func type must return an error")
+	}
+
+	y = getStructDecl(`type I interface{
+	Do(string, ...int) (int, error)
+}`)
+	m := y.Type.(*ast.InterfaceType).Methods.List[0]
+	want = "arg0 string, arg1 ...int"
+	if got := MethodParams(m); want != got {
+		t.Errorf("want %q got %q", want, got)
+	}
+	if !MethodHasEllipse(m) {
+		t.Errorf("interface method must have an ellipse")
+	}
+
+	if _, err := GetFunc("nop"); err == nil {
+		t.Errorf("want an error for an unsupported value")
+	}
+}
+
+func TestGetFuncEmbeddedInterface(t *testing.T) {
+	y := getStructDecl(`type I interface{
+	io.Reader
+}`)
+	m := y.Type.(*ast.InterfaceType).Methods.List[0]
+	if _, err := GetFunc(m); err == nil {
+		t.Errorf("want an error for an embedded interface")
+	}
+	if got := MethodName(m); got != "" {
+		t.Errorf("want %q got %q", "", got)
+	}
+	if got := MethodParams(m); got != "" {
+		t.Errorf("want %q got %q", "", got)
+	}
+	if got := GenerateWrapper(m, WrapperOptions{Target: ast.NewIdent("x")}); got != nil {
+		t.Errorf("want nil got %v", Print(got))
+	}
+}
+
+func TestGetFuncFromTypes(t *testing.T) {
+	prog := getProgramFromString(`
+import "io"
+
+type T struct{}
+func (t *T) Do(r io.Reader, n ...int) (x int, err error) { return 0, nil }
+
+var _ io.Reader
+`)
+	pkg := prog.Package("thepackagename")
+	ms := FindMethodSet(pkg, "T")
+	f, err := GetFunc(ms.Lookup("Do", true).Func)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "r io.Reader, n ...int"
+	if got := MethodParams(f); want != got {
+		t.Errorf("want %q got %q", want, got)
+	}
+	want = "t *thepackagename.T"
+	if got := GetSignature(f).Recv.String(); want != got {
+		t.Errorf("want %q got %q", want, got)
+	}
+	r, err := GetFuncQualified(ms.Lookup("Do", true).Func, types.RelativeTo(pkg.Pkg))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = "t *T"
+	if got := GetSignature(r).Recv.String(); want != got {
+		t.Errorf("want %q got %q", want, got)
+	}
+	s := GetSignature(f)
+	if got := types.TypeString(s.Params[1].T, nil); got != "[]int" {
+		t.Errorf("want %q got %q", "[]int", got)
+	}
+	if !MethodReturnError(f) {
+		t.Errorf("method must return an error")
+	}
+}
@@ -0,0 +1,144 @@
+package astutil
+
+import (
+	"fmt"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/loader"
+)
+
+// MethodMismatch is a method whose signature differs from the interface method.
+type MethodMismatch struct {
+	Want *types.Func
+	Got  *types.Func
+}
+
+// ImplementsDiff lists the methods preventing a type to implement an interface.
+type ImplementsDiff struct {
+	// Missing methods of the interface.
+	Missing []*types.Func
+	// Mismatch methods have the right name but the wrong signature.
+	Mismatch []MethodMismatch
+	// PointerOnly methods of the interface are declared with a pointer receiver,
+	// they are only available to *T.
+	PointerOnly []*types.Func
+}
+
+// OK returns true if the type implements the interface.
+func (d *ImplementsDiff) OK() bool {
+	return len(d.Missing)+len(d.Mismatch)+len(d.PointerOnly) == 0
+}
+
+// String describes the diff, one method per line.
+func (d *ImplementsDiff) String() string {
+	var ret []string
+	for _, m := range d.Missing {
+		ret = append(ret, fmt.Sprintf("missing method %v", m.Name()))
+	}
+	for _, m := range d.Mismatch {
+		ret = append(ret, fmt.Sprintf("wrong signature for method %v, want %v got %v",
+			m.Want.Name(), m.Want.Type(), m.Got.Type()))
+	}
+	for _, m := range d.PointerOnly {
+		ret = append(ret, fmt.Sprintf("method %v has a pointer receiver", m.Name()))
+	}
+	return strings.Join(ret, "\n")
+}
+
+// Implements computes the diff between the method set of t and the interface iface.
+// If t is a pointer, the pointer method set is used.
+// It returns nil if iface is not an interface.
+func Implements(t, iface types.Type) *ImplementsDiff {
+	it, ok := iface.Underlying().(*types.Interface)
+	if !ok {
+		return nil
+	}
+	_, pointer := t.(*types.Pointer)
+	ms := GetMethodSet(t)
+	ret := &ImplementsDiff{}
+	for i := 0; i < it.NumMethods(); i++ {
+		want := it.Method(i)
+		got := lookupMethodID(ms.Value, want.Id())
+		if got == nil && pointer {
+			got = lookupMethodID(ms.Pointer, want.Id())
+		}
+		if got == nil {
+			if lookupMethodID(ms.Pointer, want.Id()) != nil {
+				ret.PointerOnly = append(ret.PointerOnly, want)
+			} else {
+				ret.Missing = append(ret.Missing, want)
+			}
+		} else if !types.Identical(got.Func.Type(), want.Type()) {
+			ret.Mismatch = append(ret.Mismatch, MethodMismatch{Want: want, Got: got.Func})
+		}
+	}
+	return ret
+}
+
+// ImplementsByName computes the diff between the type named t and the interface named iface.
+// Both names are resolved within given package with GetType,
+// thus iface can be an imported interface such as io.Reader.
+func ImplementsByName(p *loader.PackageInfo, t, iface string) (*ImplementsDiff, error) {
+	x := GetType(p, t)
+	if x == nil {
+		return nil, fmt.Errorf("type %q not found", t)
+	}
+	y := GetType(p, iface)
+	if y == nil {
+		return nil, fmt.Errorf("interface %q not found", iface)
+	}
+	ret := Implements(x, y)
+	if ret == nil {
+		return nil, fmt.Errorf("type %q is not an interface", iface)
+	}
+	return ret, nil
+}
+
+// FindImplementations searches given package for every concrete types implementing iface.
+// A type whose only pointer implements iface is returned as *T.
+func FindImplementations(p *loader.PackageInfo, iface types.Type) []string {
+	ret := []string{}
+	it, ok := iface.Underlying().(*types.Interface)
+	if !ok || p.Pkg == nil {
+		return ret
+	}
+	scope := p.Pkg.Scope()
+	for _, name := range scope.Names() {
+		x, ok := scope.Lookup(name).(*types.TypeName)
+		if !ok {
+			continue
+		}
+		t := x.Type()
+		if types.IsInterface(t) {
+			continue
+		}
+		if types.Implements(t, it) {
+			ret = append(ret, name)
+		} else if types.Implements(types.NewPointer(t), it) {
+			ret = append(ret, GetPointedType(name))
+		}
+	}
+	return ret
+}
+
+// FindImplementationsByName is FindImplementations with an interface name resolved by GetType.
+func FindImplementationsByName(p *loader.PackageInfo, iface string) ([]string, error) {
+	y := GetType(p, iface)
+	if y == nil {
+		return nil, fmt.Errorf("interface %q not found", iface)
+	}
+	if !types.IsInterface(y) {
+		return nil, fmt.Errorf("type %q is not an interface", iface)
+	}
+	return FindImplementations(p, y), nil
+}
+
+func lookupMethodID(l []*Method, id string) *Method {
+	for _, m := range l {
+		if m.Func.Id() == id {
+			return m
+		}
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package astutil
+
+import "testing"
+
+func TestImplementsByName(t *testing.T) {
+	prog := getProgramFromString(`
+import "io"
+
+type R struct{}
+func (r *R) Read(p []byte) (int, error) { return 0, nil }
+
+type W struct{}
+func (w W) Write(p []byte) error { return nil }
+
+var _ io.Reader
+`)
+	pkg := prog.Package("thepackagename")
+
+	diff, err := ImplementsByName(pkg, "*R", "io.Reader")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !diff.OK() {
+		t.Errorf("*R must implement io.Reader, got %v", diff)
+	}
+
+	diff, err = ImplementsByName(pkg, "R", "io.Reader")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(diff.PointerOnly); got != 1 {
+		t.Errorf("want %v pointer only methods got %v", 1, got)
+	}
+
+	diff, err = ImplementsByName(pkg, "W", "io.Writer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(diff.Mismatch); got != 1 {
+		t.Errorf("want %v mismatching methods got %v", 1, got)
+	}
+
+	diff, err = ImplementsByName(pkg, "W", "io.Reader")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(diff.Missing); got != 1 {
+		t.Errorf("want %v missing methods got %v", 1, got)
+	}
+
+	if _, err := ImplementsByName(pkg, "W", "R"); err == nil {
+		t.Errorf("want an error for a non interface type")
+	}
+}
+
+func TestFindImplementations(t *testing.T) {
+	prog := getProgramFromString(`
+type I interface{ Do() }
+
+type A struct{}
+func (a A) Do() {}
+
+type B struct{}
+func (b *B) Do() {}
+
+type C struct{}
+`)
+	pkg := prog.Package("thepackagename")
+	got, err := FindImplementationsByName(pkg, "I")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"A", "*B"}
+	if len(want) != len(got) {
+		t.Fatalf("want %v got %v", want, got)
+	}
+	for i := range want {
+		if want[i] != got[i] {
+			t.Errorf("want %v got %v", want[i], got[i])
+		}
+	}
+}
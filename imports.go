@@ -0,0 +1,184 @@
+package astutil
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"path"
+	"sort"
+	"strconv"
+
+	"golang.org/x/tools/go/loader"
+)
+
+// Imports collects the imports of a generated file.
+type Imports struct {
+	paths map[string]string // name => path
+	err   error
+}
+
+// NewImports creates an empty import list.
+func NewImports() *Imports {
+	return &Imports{paths: map[string]string{}}
+}
+
+// Add the import path with identifier name.
+// When name is empty, the last element of the path is used.
+// It fails if name is already used by another path.
+func (i *Imports) Add(name, importPath string) error {
+	if name == "" {
+		name = path.Base(importPath)
+	}
+	if x, ok := i.paths[name]; ok && x != importPath {
+		return fmt.Errorf("import name %q conflicts: %q and %q", name, x, importPath)
+	}
+	i.paths[name] = importPath
+	return nil
+}
+
+// AddIdentifiers adds the imports of the package identifiers ids as they are imported by p.
+func (i *Imports) AddIdentifiers(p *loader.PackageInfo, ids ...string) error {
+	for _, id := range ids {
+		importPath, err := ResolveImportPath(p, id)
+		if err != nil {
+			return err
+		}
+		if err := i.Add(id, importPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AddSignature adds the imports of the signature of m, as they are imported by p.
+// m is a func like node supported by GetFunc,
+// the packages of a type checked signature are added as they are imported by p.
+func (i *Imports) AddSignature(p *loader.PackageInfo, m ast.Node) error {
+	f, err := GetFunc(m)
+	if err != nil {
+		return err
+	}
+	if s := f.Signature; s != nil {
+		types.TypeString(types.NewSignatureType(nil, nil, nil, s.Params(), s.Results(), s.Variadic()), i.PackageQualifier(p))
+		return i.Err()
+	}
+	return i.AddIdentifiers(p, GetSignatureImportIdentifiers(f)...)
+}
+
+// Qualifier returns a types.Qualifier relative to pkg,
+// it adds the imports of the packages it qualifies.
+// Conflicting imports are reported by Err.
+func (i *Imports) Qualifier(pkg *types.Package) types.Qualifier {
+	return func(x *types.Package) string {
+		if x == pkg {
+			return ""
+		}
+		if err := i.Add(x.Name(), x.Path()); err != nil && i.err == nil {
+			i.err = err
+		}
+		return x.Name()
+	}
+}
+
+// PackageQualifier is Qualifier relative to p.Pkg,
+// the packages are named as they are imported by the files of p.
+func (i *Imports) PackageQualifier(p *loader.PackageInfo) types.Qualifier {
+	names := map[string]string{}
+	for _, f := range p.Files {
+		for _, x := range f.Imports {
+			if x.Name != nil && x.Name.Name != "_" && x.Name.Name != "." {
+				if importPath, err := strconv.Unquote(x.Path.Value); err == nil {
+					names[importPath] = x.Name.Name
+				}
+			}
+		}
+	}
+	return func(x *types.Package) string {
+		if x == p.Pkg {
+			return ""
+		}
+		name, ok := names[x.Path()]
+		if !ok {
+			name = x.Name()
+		}
+		if err := i.Add(name, x.Path()); err != nil && i.err == nil {
+			i.err = err
+		}
+		return name
+	}
+}
+
+// Err returns the first conflict met by a Qualifier.
+func (i *Imports) Err() error {
+	return i.err
+}
+
+// Len returns the number of imports.
+func (i *Imports) Len() int {
+	return len(i.paths)
+}
+
+// Specs returns the import specs sorted by path.
+func (i *Imports) Specs() []*ast.ImportSpec {
+	var names []string
+	for name := range i.paths {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(a, b int) bool {
+		return i.paths[names[a]] < i.paths[names[b]]
+	})
+	var ret []*ast.ImportSpec
+	for _, name := range names {
+		x := &ast.ImportSpec{
+			Path: &ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(i.paths[name])},
+		}
+		if path.Base(i.paths[name]) != name {
+			x.Name = ast.NewIdent(name)
+		}
+		ret = append(ret, x)
+	}
+	return ret
+}
+
+// Decl returns the import declaration, it is nil when there is no imports.
+func (i *Imports) Decl() *ast.GenDecl {
+	if i.Len() == 0 {
+		return nil
+	}
+	ret := &ast.GenDecl{Tok: token.IMPORT, Lparen: 1}
+	for _, x := range i.Specs() {
+		ret.Specs = append(ret.Specs, x)
+	}
+	return ret
+}
+
+// String returns the import declaration as a string.
+func (i *Imports) String() string {
+	if i.Len() == 0 {
+		return ""
+	}
+	var b bytes.Buffer
+	b.WriteString("import (\n")
+	for _, x := range i.Specs() {
+		if x.Name != nil {
+			b.WriteString(x.Name.Name + " ")
+		}
+		b.WriteString(x.Path.Value + "\n")
+	}
+	b.WriteString(")\n")
+	return b.String()
+}
+
+// ResolveImportPath returns the import path of the package identifier id as imported by p.
+// It fails when p does not import id.
+func ResolveImportPath(p *loader.PackageInfo, id string) (string, error) {
+	if ret := GetImportPath(p, id); ret != "" {
+		return ret, nil
+	}
+	if pkg := getImportedPkg(p, id); pkg != nil {
+		return pkg.Path(), nil
+	}
+	return "", fmt.Errorf("package %q is not imported by %v", id, p.Pkg.Path())
+}
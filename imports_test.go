@@ -0,0 +1,57 @@
+package astutil
+
+import "testing"
+
+func TestImports(t *testing.T) {
+	i := NewImports()
+	if err := i.Add("", "io"); err != nil {
+		t.Fatal(err)
+	}
+	if err := i.Add("yaml", "gopkg.in/yaml.v2"); err != nil {
+		t.Fatal(err)
+	}
+	if err := i.Add("io", "io"); err != nil {
+		t.Fatal(err)
+	}
+	if err := i.Add("io", "other/io"); err == nil {
+		t.Errorf("want a conflict error")
+	}
+	want := `import (
+yaml "gopkg.in/yaml.v2"
+"io"
+)
+`
+	if got := i.String(); want != got {
+		t.Errorf("want=\n%v\ngot=\n%v", want, got)
+	}
+	if got := NewImports().String(); got != "" {
+		t.Errorf("want empty got %q", got)
+	}
+}
+
+func TestImportsAddSignature(t *testing.T) {
+	prog := getProgramFromString(`
+import "io"
+
+type T struct{}
+func (t *T) Do(r io.Reader) (*T, error) { return nil, nil }
+
+var _ io.Reader
+`)
+	pkg := prog.Package("thepackagename")
+	m, err := GetFunc(FindMethodSet(pkg, "T").Lookup("Do", true).Func)
+	if err != nil {
+		t.Fatal(err)
+	}
+	i := NewImports()
+	if err := i.AddSignature(pkg, m); err != nil {
+		t.Fatal(err)
+	}
+	want := "import (\n\"io\"\n)\n"
+	if got := i.String(); want != got {
+		t.Errorf("want=\n%v\ngot=\n%v", want, got)
+	}
+	if _, err := ResolveImportPath(pkg, "nop"); err == nil {
+		t.Errorf("want an error")
+	}
+}
@@ -0,0 +1,115 @@
+package astutil
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/types"
+	"path"
+	"sort"
+
+	"golang.org/x/tools/go/loader"
+)
+
+// InterfaceOptions configures GenerateInterface.
+type InterfaceOptions struct {
+	// Name of the interface, it defaults to the type name suffixed with Interface.
+	Name string
+	// Annotation filters the methods having this annotation in their doc, such as @iface.
+	Annotation string
+	// Pattern filters the methods whose name matches this path.Match pattern.
+	Pattern string
+	// Value restricts the methods to the method set of T,
+	// by default the method set of *T is used.
+	Value bool
+}
+
+// GenerateInterface generates the source of a file declaring
+// an interface of the exported methods of the type t.
+// The methods are those of the method set of *T, including the promoted methods,
+// or of T with o.Value.
+// Doc comments of the methods declared in p are copied over.
+func GenerateInterface(p *loader.PackageInfo, t string, o InterfaceOptions) ([]byte, error) {
+	t = GetUnpointedType(t)
+	if o.Name == "" {
+		o.Name = t + "Interface"
+	}
+	ms := FindMethodSet(p, t)
+	if ms == nil {
+		return nil, fmt.Errorf("type %q not found", t)
+	}
+	methods := append([]*Method{}, ms.Pointer...)
+	if o.Value {
+		methods = append([]*Method{}, ms.Value...)
+	}
+	// the methods declared in p keep their declaration order,
+	// the others follow by name.
+	sort.SliceStable(methods, func(i, j int) bool {
+		a, b := methods[i].Func, methods[j].Func
+		if (a.Pkg() == p.Pkg) != (b.Pkg() == p.Pkg) {
+			return a.Pkg() == p.Pkg
+		}
+		return a.Pkg() == p.Pkg && a.Pos() < b.Pos()
+	})
+	docs := map[types.Object]*ast.CommentGroup{}
+	for _, file := range p.Files {
+		for _, decl := range file.Decls {
+			if x, ok := decl.(*ast.FuncDecl); ok && x.Recv != nil {
+				docs[p.Defs[x.Name]] = x.Doc
+			}
+		}
+	}
+	imports := NewImports()
+	q := imports.PackageQualifier(p)
+	var body bytes.Buffer
+	for _, m := range methods {
+		if !IsExported(m.Name) {
+			continue
+		}
+		doc := docs[m.Func]
+		if o.Annotation != "" && !HasAnnotation(doc.Text(), o.Annotation) {
+			continue
+		}
+		if o.Pattern != "" {
+			if ok, err := path.Match(o.Pattern, m.Name); err != nil {
+				return nil, err
+			} else if !ok {
+				continue
+			}
+		}
+		writeDoc(&body, doc)
+		body.WriteString(methodSpec(m.Name, methodSignature(m.Func, q)) + "\n")
+	}
+	if err := imports.Err(); err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "package %v\n\n", p.Pkg.Name())
+	b.WriteString(imports.String())
+	fmt.Fprintf(&b, "\n// %v is an interface of %v.\n", o.Name, t)
+	fmt.Fprintf(&b, "type %v interface {\n", o.Name)
+	b.Write(body.Bytes())
+	b.WriteString("}\n")
+	return format.Source(b.Bytes())
+}
+
+// methodSpec returns the method s as declared in an interface.
+func methodSpec(name string, s *Signature) string {
+	ret := name + "(" + s.ParamsString() + ")"
+	if r := s.ResultsString(); r != "" {
+		ret += " " + r
+	}
+	return ret
+}
+
+// writeDoc writes the comment lines of doc.
+func writeDoc(b *bytes.Buffer, doc *ast.CommentGroup) {
+	if doc == nil {
+		return
+	}
+	for _, c := range doc.List {
+		b.WriteString(c.Text + "\n")
+	}
+}
@@ -0,0 +1,150 @@
+package astutil
+
+import "testing"
+
+func TestGenerateInterface(t *testing.T) {
+	prog := getProgramFromString(`
+import (
+	"io"
+	jsonenc "encoding/json"
+)
+
+type T struct{}
+
+// Read reads.
+// @iface
+func (t *T) Read(r io.Reader) error { return nil }
+
+// Encode encodes.
+func (t T) Encode(e *jsonenc.Encoder) {}
+
+func (t *T) hidden() {}
+
+var _ io.Reader
+var _ jsonenc.Encoder
+`)
+	pkg := prog.Package("thepackagename")
+	got, err := GenerateInterface(pkg, "T", InterfaceOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `package thepackagename
+
+import (
+	jsonenc "encoding/json"
+	"io"
+)
+
+// TInterface is an interface of T.
+type TInterface interface {
+	// Read reads.
+	// @iface
+	Read(r io.Reader) error
+	// Encode encodes.
+	Encode(e *jsonenc.Encoder)
+}
+`
+	if want != string(got) {
+		t.Errorf("want=\n%v\ngot=\n%v", want, string(got))
+	}
+
+	got, err = GenerateInterface(pkg, "T", InterfaceOptions{Name: "Reader", Annotation: "@iface"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = `package thepackagename
+
+import (
+	"io"
+)
+
+// Reader is an interface of T.
+type Reader interface {
+	// Read reads.
+	// @iface
+	Read(r io.Reader) error
+}
+`
+	if want != string(got) {
+		t.Errorf("want=\n%v\ngot=\n%v", want, string(got))
+	}
+
+	got, err = GenerateInterface(pkg, "T", InterfaceOptions{Pattern: "En*"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = `package thepackagename
+
+import (
+	jsonenc "encoding/json"
+)
+
+// TInterface is an interface of T.
+type TInterface interface {
+	// Encode encodes.
+	Encode(e *jsonenc.Encoder)
+}
+`
+	if want != string(got) {
+		t.Errorf("want=\n%v\ngot=\n%v", want, string(got))
+	}
+}
+
+func TestGenerateInterfacePromoted(t *testing.T) {
+	prog := getProgramFromString(`
+type Base struct{}
+
+// Close closes.
+func (b *Base) Close() error { return nil }
+
+// ID returns the id.
+func (b Base) ID() string { return "" }
+
+type T struct {
+	Base
+}
+
+// Do does.
+func (t *T) Do() {}
+`)
+	pkg := prog.Package("thepackagename")
+	got, err := GenerateInterface(pkg, "*T", InterfaceOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `package thepackagename
+
+// TInterface is an interface of T.
+type TInterface interface {
+	// Close closes.
+	Close() error
+	// ID returns the id.
+	ID() string
+	// Do does.
+	Do()
+}
+`
+	if want != string(got) {
+		t.Errorf("want=\n%v\ngot=\n%v", want, string(got))
+	}
+
+	got, err = GenerateInterface(pkg, "T", InterfaceOptions{Value: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = `package thepackagename
+
+// TInterface is an interface of T.
+type TInterface interface {
+	// ID returns the id.
+	ID() string
+}
+`
+	if want != string(got) {
+		t.Errorf("want=\n%v\ngot=\n%v", want, string(got))
+	}
+
+	if _, err := GenerateInterface(pkg, "Nop", InterfaceOptions{}); err == nil {
+		t.Errorf("want an error")
+	}
+}
@@ -0,0 +1,124 @@
+package astutil
+
+import (
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/loader"
+)
+
+// Method is a method found in the method set of a type.
+type Method struct {
+	Name string
+	Func *types.Func
+	// Recv is the receiver type of the method declaration.
+	Recv types.Type
+	// Path lists the embedded fields traversed to reach the method,
+	// it is empty when the method is declared on the type itself.
+	Path []string
+}
+
+// PointerReceiver returns true if the method is declared with a pointer receiver.
+func (m *Method) PointerReceiver() bool {
+	_, ok := m.Recv.(*types.Pointer)
+	return ok
+}
+
+// Promoted returns true if the method is promoted from an embedded field.
+func (m *Method) Promoted() bool {
+	return len(m.Path) > 0
+}
+
+// Origin describes where the method comes from,
+// declared or promoted through its embedding path.
+func (m *Method) Origin() string {
+	if m.Promoted() {
+		return "promoted through " + strings.Join(m.Path, ".")
+	}
+	return "declared"
+}
+
+// MethodSet holds the method sets of a type T and of *T.
+type MethodSet struct {
+	// Type is the non pointer type T.
+	Type    types.Type
+	Value   []*Method
+	Pointer []*Method
+}
+
+// Lookup the method named n in the method set of T, or *T if pointer is true.
+func (s *MethodSet) Lookup(n string, pointer bool) *Method {
+	l := s.Value
+	if pointer {
+		l = s.Pointer
+	}
+	for _, m := range l {
+		if m.Name == n {
+			return m
+		}
+	}
+	return nil
+}
+
+// GetMethodSet computes the method sets of t and *t.
+// A pointer type is always dereferenced.
+func GetMethodSet(t types.Type) *MethodSet {
+	if y, ok := t.(*types.Pointer); ok {
+		t = y.Elem()
+	}
+	return &MethodSet{
+		Type:    t,
+		Value:   methodSetOf(t, t),
+		Pointer: methodSetOf(t, types.NewPointer(t)),
+	}
+}
+
+// FindMethodSet computes the method sets of the type named t in given package.
+// It returns nil if the type is not found.
+func FindMethodSet(p *loader.PackageInfo, t string) *MethodSet {
+	x := GetType(p, t)
+	if x == nil {
+		return nil
+	}
+	return GetMethodSet(x)
+}
+
+func methodSetOf(base, t types.Type) []*Method {
+	var ret []*Method
+	ms := types.NewMethodSet(t)
+	for i := 0; i < ms.Len(); i++ {
+		sel := ms.At(i)
+		f, ok := sel.Obj().(*types.Func)
+		if !ok {
+			continue
+		}
+		m := &Method{
+			Name: f.Name(),
+			Func: f,
+			Path: embeddingPath(base, sel.Index()),
+		}
+		if recv := f.Type().(*types.Signature).Recv(); recv != nil {
+			m.Recv = recv.Type()
+		}
+		ret = append(ret, m)
+	}
+	return ret
+}
+
+// embeddingPath resolves the names of the embedded fields in index.
+func embeddingPath(t types.Type, index []int) []string {
+	var ret []string
+	for _, i := range index[:len(index)-1] {
+		if y, ok := t.(*types.Pointer); ok {
+			t = y.Elem()
+		}
+		s, ok := t.Underlying().(*types.Struct)
+		if !ok {
+			break
+		}
+		f := s.Field(i)
+		ret = append(ret, f.Name())
+		t = f.Type()
+	}
+	return ret
+}
@@ -0,0 +1,53 @@
+package astutil
+
+import "testing"
+
+func TestFindMethodSet(t *testing.T) {
+	prog := getProgramFromString(`
+type A struct{}
+func (a A) Value() {}
+func (a *A) Ptr() {}
+
+type B struct{
+	A
+}
+func (b *B) Own() {}
+`)
+	pkg := prog.Package("thepackagename")
+	ms := FindMethodSet(pkg, "B")
+	if ms == nil {
+		t.Fatal("method set not found")
+	}
+	if got := len(ms.Value); got != 1 {
+		t.Errorf("want %v value methods got %v", 1, got)
+	}
+	if got := len(ms.Pointer); got != 3 {
+		t.Errorf("want %v pointer methods got %v", 3, got)
+	}
+	if ms.Lookup("Ptr", false) != nil {
+		t.Errorf("Ptr must not be in the value method set")
+	}
+	m := ms.Lookup("Ptr", true)
+	if m == nil {
+		t.Fatal("Ptr not found in the pointer method set")
+	}
+	if !m.PointerReceiver() {
+		t.Errorf("Ptr must have a pointer receiver")
+	}
+	want := "promoted through A"
+	if got := m.Origin(); got != want {
+		t.Errorf("want %q got %q", want, got)
+	}
+	want = "declared"
+	if got := ms.Lookup("Own", true).Origin(); got != want {
+		t.Errorf("want %q got %q", want, got)
+	}
+}
+
+func TestFindMethodSetNotFound(t *testing.T) {
+	prog := getProgramFromString(`type A struct{}`)
+	pkg := prog.Package("thepackagename")
+	if ms := FindMethodSet(pkg, "C"); ms != nil {
+		t.Errorf("want nil got %v", ms)
+	}
+}
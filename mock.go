@@ -0,0 +1,200 @@
+package astutil
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/types"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/tools/go/loader"
+)
+
+// MockOptions configures GenerateMock.
+type MockOptions struct {
+	// Name of the mock type, it defaults to the interface name prefixed with Mock.
+	Name string
+}
+
+// GenerateMock generates the source of a file declaring a mock of the interface iface.
+// iface is resolved with GetType, it can be an imported interface.
+// For each method Do of the interface, the mock has
+// a DoFunc field invoked when set,
+// a DoResults field holding the results returned when DoFunc is nil,
+// a DoCalls field recording the arguments of each call,
+// a DoCallCount method and an AssertDoCalled method.
+func GenerateMock(p *loader.PackageInfo, iface string, o MockOptions) ([]byte, error) {
+	t := GetType(p, iface)
+	if t == nil {
+		return nil, fmt.Errorf("interface %q not found", iface)
+	}
+	it, ok := t.Underlying().(*types.Interface)
+	if !ok {
+		return nil, fmt.Errorf("type %q is not an interface", iface)
+	}
+	if o.Name == "" {
+		o.Name = "Mock" + iface[strings.LastIndex(iface, ".")+1:]
+	}
+
+	imports := NewImports()
+	q := imports.Qualifier(p.Pkg)
+	if err := imports.Add("", "sync"); err != nil {
+		return nil, err
+	}
+	fields := NewNamer("mu")
+	for i := 0; i < it.NumMethods(); i++ {
+		fields.Reserve(it.Method(i).Name())
+	}
+
+	var decl, body bytes.Buffer
+	fmt.Fprintf(&decl, "// %v is a mock of %v.\n", o.Name, iface)
+	fmt.Fprintf(&decl, "type %v struct {\n", o.Name)
+	decl.WriteString("mu sync.Mutex\n")
+	for i := 0; i < it.NumMethods(); i++ {
+		m := it.Method(i)
+		s := methodSignature(m, q)
+		callType := o.Name + m.Name() + "Call"
+		g := &mockMethod{
+			mock:      o.Name,
+			name:      m.Name(),
+			sig:       s,
+			vars:      mockVars(s, callType),
+			funcField: fields.Name(m.Name() + "Func"),
+			calls:     fields.Name(m.Name() + "Calls"),
+			callType:  callType,
+			count:     fields.Name(m.Name() + "CallCount"),
+			assert:    fields.Name("Assert" + m.Name() + "Called"),
+		}
+		if len(s.Results) > 0 {
+			g.results = fields.Name(m.Name() + "Results")
+		}
+		g.writeFields(&decl)
+		g.writeMethods(&body)
+	}
+	decl.WriteString("}\n")
+	if err := imports.Err(); err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "package %v\n\n", p.Pkg.Name())
+	b.WriteString(imports.String())
+	b.WriteString("\n")
+	b.Write(decl.Bytes())
+	b.Write(body.Bytes())
+	return format.Source(b.Bytes())
+}
+
+// mockMethod generates the code of a mocked method.
+type mockMethod struct {
+	mock      string
+	name      string
+	sig       *Signature
+	vars      *Namer
+	funcField string
+	results   string
+	calls     string
+	callType  string
+	count     string
+	assert    string
+}
+
+// mockVars returns the Namer of the body of a mocked method of signature s,
+// the names of s shadowing the predeclared identifiers or the call type are renamed.
+func mockVars(s *Signature, callType string) *Namer {
+	shadowed := map[string]bool{callType: true}
+	for _, name := range types.Universe.Names() {
+		shadowed[name] = true
+	}
+	ret := NewNamer(callType)
+	ret.Reserve(types.Universe.Names()...)
+	for _, p := range s.all() {
+		if !shadowed[p.Name] {
+			ret.Reserve(p.Name)
+		}
+	}
+	for _, p := range s.all() {
+		if shadowed[p.Name] {
+			p.Name = ret.Name(p.Name)
+		}
+	}
+	return ret
+}
+
+func (g *mockMethod) writeFields(b *bytes.Buffer) {
+	fmt.Fprintf(b, "// %v, when set, is invoked by %v.\n", g.funcField, g.name)
+	fmt.Fprintf(b, "%v %v\n", g.funcField, g.sig)
+	if g.results != "" {
+		fmt.Fprintf(b, "// %v are returned by %v when %v is nil.\n", g.results, g.name, g.funcField)
+		fmt.Fprintf(b, "%v struct {\n", g.results)
+		for i, r := range g.sig.Results {
+			fmt.Fprintf(b, "R%v %v\n", i, r.TypeString)
+		}
+		b.WriteString("}\n")
+	}
+	fmt.Fprintf(b, "// %v records the calls of %v.\n", g.calls, g.name)
+	fmt.Fprintf(b, "%v []%v\n", g.calls, g.callType)
+}
+
+func (g *mockMethod) writeMethods(b *bytes.Buffer) {
+	props := NewNamer()
+	var callFields, callValues []string
+	for _, p := range g.sig.Params {
+		name := props.Name(upperFirst(p.Name))
+		t := p.TypeString
+		if p.Variadic {
+			t = "[]" + ToString(p.Type.(*ast.Ellipsis).Elt)
+		}
+		callFields = append(callFields, name+" "+t+"\n")
+		callValues = append(callValues, name+": "+p.Name)
+	}
+	fmt.Fprintf(b, "\n// %v records a call of %v.%v.\n", g.callType, g.mock, g.name)
+	fmt.Fprintf(b, "type %v struct {\n%v}\n", g.callType, strings.Join(callFields, ""))
+
+	recv := g.vars.Name("m")
+	f := g.vars.Name("f")
+	r := g.vars.Name("r")
+
+	fmt.Fprintf(b, "\n// %v is a mock of the method %v.\n", g.name, g.name)
+	fmt.Fprintf(b, "func (%v *%v) %v {\n", recv, g.mock, methodSpec(g.name, g.sig))
+	fmt.Fprintf(b, "%v.mu.Lock()\n", recv)
+	fmt.Fprintf(b, "%v.%v = append(%v.%v, %v{%v})\n",
+		recv, g.calls, recv, g.calls, g.callType, strings.Join(callValues, ", "))
+	fmt.Fprintf(b, "%v := %v.%v\n", f, recv, g.funcField)
+	if g.results != "" {
+		fmt.Fprintf(b, "%v := %v.%v\n", r, recv, g.results)
+	}
+	fmt.Fprintf(b, "%v.mu.Unlock()\n", recv)
+	invoke := fmt.Sprintf("%v(%v)", f, g.sig.Invokation(true))
+	if g.results != "" {
+		fmt.Fprintf(b, "if %v != nil {\nreturn %v\n}\n", f, invoke)
+		var ret []string
+		for i := range g.sig.Results {
+			ret = append(ret, fmt.Sprintf("%v.R%v", r, i))
+		}
+		fmt.Fprintf(b, "return %v\n", strings.Join(ret, ", "))
+	} else {
+		fmt.Fprintf(b, "if %v != nil {\n%v\n}\n", f, invoke)
+	}
+	b.WriteString("}\n")
+
+	fmt.Fprintf(b, "\n// %v returns the number of calls of %v.\n", g.count, g.name)
+	fmt.Fprintf(b, "func (m *%v) %v() int {\n", g.mock, g.count)
+	b.WriteString("m.mu.Lock()\ndefer m.mu.Unlock()\n")
+	fmt.Fprintf(b, "return len(m.%v)\n}\n", g.calls)
+
+	fmt.Fprintf(b, "\n// %v reports an error to t if %v was not called n times.\n", g.assert, g.name)
+	fmt.Fprintf(b, "func (m *%v) %v(t interface{ Errorf(string, ...interface{}) }, n int) bool {\n", g.mock, g.assert)
+	fmt.Fprintf(b, "if c := m.%v(); c != n {\n", g.count)
+	fmt.Fprintf(b, "t.Errorf(\"%v.%v: want %%v calls got %%v\", n, c)\n", g.mock, g.name)
+	b.WriteString("return false\n}\nreturn true\n}\n")
+}
+
+// upperFirst upper cases the first letter of s.
+func upperFirst(s string) string {
+	r, n := utf8.DecodeRuneInString(s)
+	return string(unicode.ToUpper(r)) + s[n:]
+}
@@ -0,0 +1,93 @@
+package astutil
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateMock(t *testing.T) {
+	src := `
+type I interface {
+	io.Reader
+	Do(string, ...int) (int, error)
+	Close()
+}
+
+var _ io.Reader
+`
+	prog := getProgramFromString(`import "io"` + src)
+	pkg := prog.Package("thepackagename")
+	got, err := GenerateMock(pkg, "I", MockOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"type MockI struct {",
+		"DoFunc func(arg0 string, arg1 ...int) (int, error)",
+		"ReadResults struct {",
+		"func (m *MockI) Do(arg0 string, arg1 ...int) (int, error) {",
+		"return f(arg0, arg1...)",
+		"type MockIDoCall struct {",
+		"Arg1 []int",
+		"func (m *MockI) AssertCloseCalled(",
+	} {
+		if !strings.Contains(string(got), want) {
+			t.Errorf("generated mock does not contain %q\n%s", want, got)
+		}
+	}
+
+	assertCompiles(t, prog, got)
+}
+
+func TestGenerateMockCompiles(t *testing.T) {
+	prog := getProgramFromString(`
+import "io"
+
+type I interface {
+	io.Closer
+	Do(m int, f func(), r string) (n int, err error)
+	DoFunc()
+	Mu()
+}
+
+var _ io.Reader
+`)
+	pkg := prog.Package("thepackagename")
+	got, err := GenerateMock(pkg, "I", MockOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(got), `"io"`) {
+		t.Errorf("the mock must not import io\n%s", got)
+	}
+	assertCompiles(t, prog, got)
+}
+
+func TestGenerateMockPredeclaredParams(t *testing.T) {
+	prog := getProgramFromString(`
+type I interface {
+	M(append int, len string, make, error bool, MockIMCall int) (int, error)
+}
+`)
+	pkg := prog.Package("thepackagename")
+	got, err := GenerateMock(pkg, "I", MockOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "func (m *MockI) M(append1 int, len1 string, make1 bool, error1 bool, MockIMCall1 int) (int, error) {"
+	if !strings.Contains(string(got), want) {
+		t.Errorf("generated mock does not contain %q\n%s", want, got)
+	}
+	assertCompiles(t, prog, got)
+}
+
+func TestGenerateMockNotAnInterface(t *testing.T) {
+	prog := getProgramFromString(`type T struct{}`)
+	pkg := prog.Package("thepackagename")
+	if _, err := GenerateMock(pkg, "T", MockOptions{}); err == nil {
+		t.Errorf("want an error")
+	}
+	if _, err := GenerateMock(pkg, "Nop", MockOptions{}); err == nil {
+		t.Errorf("want an error")
+	}
+}
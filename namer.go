@@ -0,0 +1,92 @@
+package astutil
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"strconv"
+	"sync"
+
+	"golang.org/x/tools/go/loader"
+)
+
+// Namer allocates unique identifiers within a scope,
+// such as the body of a generated func.
+// It is safe for concurrent use.
+type Namer struct {
+	mu   sync.Mutex
+	used map[string]bool
+}
+
+// NewNamer creates a Namer, reserved names are never allocated.
+func NewNamer(reserved ...string) *Namer {
+	ret := &Namer{used: map[string]bool{}}
+	ret.Reserve(reserved...)
+	return ret
+}
+
+// NewFuncNamer creates a Namer for the body of func m, a func like node supported by GetFunc.
+// It reserves the names of the receiver, the params, the results
+// and the predeclared identifiers.
+// If p is not nil, the names of the packages imported by p are reserved too.
+func NewFuncNamer(p *loader.PackageInfo, m ast.Node) *Namer {
+	ret := NewNamer(types.Universe.Names()...)
+	for _, x := range GetSignature(m).all() {
+		ret.Reserve(x.Name)
+	}
+	if p != nil {
+		ret.Reserve(importNames(p)...)
+	}
+	return ret
+}
+
+// Reserve marks names as used.
+func (n *Namer) Reserve(names ...string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for _, name := range names {
+		n.used[name] = true
+	}
+}
+
+// Name allocates base, or baseN with the smallest N starting from 1 if base is in use.
+func (n *Namer) Name(base string) string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	ret := base
+	for i := 1; n.used[ret]; i++ {
+		ret = base + strconv.Itoa(i)
+	}
+	n.used[ret] = true
+	return ret
+}
+
+// Next allocates prefixN with the smallest N starting from 0.
+func (n *Namer) Next(prefix string) string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	ret := ""
+	for i := 0; ret == "" || n.used[ret]; i++ {
+		ret = fmt.Sprintf("%v%v", prefix, i)
+	}
+	n.used[ret] = true
+	return ret
+}
+
+// importNames returns the identifiers of the packages imported by p.
+func importNames(p *loader.PackageInfo) []string {
+	var ret []string
+	if p.Pkg != nil {
+		for _, pkg := range p.Pkg.Imports() {
+			ret = append(ret, pkg.Name())
+		}
+	}
+	for _, file := range p.Files {
+		for _, i := range file.Imports {
+			if i.Name != nil {
+				ret = append(ret, i.Name.Name)
+			}
+		}
+	}
+	return ret
+}
@@ -0,0 +1,76 @@
+package astutil
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestNamer(t *testing.T) {
+	n := NewNamer("x", "y0")
+	tests := []struct {
+		got  string
+		want string
+	}{
+		{n.Name("x"), "x1"},
+		{n.Name("x"), "x2"},
+		{n.Name("z"), "z"},
+		{n.Next("y"), "y1"},
+		{n.Next("y"), "y2"},
+	}
+	for _, test := range tests {
+		if test.want != test.got {
+			t.Errorf("want %v got %v", test.want, test.got)
+		}
+	}
+}
+
+func TestNamerConcurrent(t *testing.T) {
+	n := NewNamer()
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	seen := map[string]bool{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			name := n.Next("v")
+			mu.Lock()
+			defer mu.Unlock()
+			if seen[name] {
+				t.Errorf("name %v allocated twice", name)
+			}
+			seen[name] = true
+		}()
+	}
+	wg.Wait()
+}
+
+func TestMethodReturnVars(t *testing.T) {
+	y := getFuncDecl(`func t(retVar0 string) (int, error) {}`)
+	for i := 0; i < 2; i++ {
+		want := []string{"retVar1", "retVar2"}
+		got := MethodReturnVars(y)
+		if len(want) != len(got) {
+			t.Fatalf("want %v got %v", want, got)
+		}
+		for i := range want {
+			if want[i] != got[i] {
+				t.Errorf("want %v got %v", want[i], got[i])
+			}
+		}
+	}
+}
+
+func TestMethodReturnNamesNormalized(t *testing.T) {
+	y := getFuncDecl(`func t() (int, error) {}`)
+	want := []string{"retVar0", "retVar1"}
+	got := MethodReturnNamesNormalized(y)
+	if len(want) != len(got) {
+		t.Fatalf("want %v got %v", want, got)
+	}
+	for i := range want {
+		if want[i] != got[i] {
+			t.Errorf("want %v got %v", want[i], got[i])
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package astutil
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"strings"
+
+	"golang.org/x/tools/go/loader"
+)
+
+// FunctionalOptions describes the functional options pattern of a type.
+type FunctionalOptions struct {
+	// Type is the name of the configured type T.
+	Type string
+	// Option is the name of the option type, type Option func(*T).
+	Option string
+	// Ctors of T accepting a variadic list of options.
+	Ctors []*ast.FuncDecl
+	// Options are the funcs returning an Option, by name.
+	Options map[string]*ast.FuncDecl
+}
+
+// FindFunctionalOptions searches given package for the functional options of type t.
+// It returns nil if no option type is declared for t.
+func FindFunctionalOptions(p *loader.PackageInfo, t string) *FunctionalOptions {
+	option := findOptionType(p, t)
+	if option == "" {
+		return nil
+	}
+	ret := &FunctionalOptions{
+		Type:    t,
+		Option:  option,
+		Options: map[string]*ast.FuncDecl{},
+	}
+	for _, ctor := range FindConstructors(p, []string{t})[t] {
+		if isOptionsCtor(ctor, option) {
+			ret.Ctors = append(ret.Ctors, ctor)
+		}
+	}
+	for _, file := range p.Files {
+		for _, decl := range file.Decls {
+			x, ok := decl.(*ast.FuncDecl)
+			if !ok || x.Recv != nil {
+				continue
+			}
+			r := MethodReturnTypes(x)
+			if len(r) == 1 && r[0] == option {
+				ret.Options[MethodName(x)] = x
+			}
+		}
+	}
+	return ret
+}
+
+// GenerateOptions generates the missing WithX option funcs of type t,
+// one for each exported field of the struct.
+func GenerateOptions(p *loader.PackageInfo, t string) ([]*ast.FuncDecl, error) {
+	opts := FindFunctionalOptions(p, t)
+	if opts == nil {
+		return nil, fmt.Errorf("no option type found for %q", t)
+	}
+	s := GetStruct(p, t)
+	if s == nil {
+		return nil, fmt.Errorf("struct %q not found", t)
+	}
+	var ret []*ast.FuncDecl
+	for _, f := range s.Fields.List {
+		for _, n := range f.Names {
+			if !IsExported(n.Name) {
+				continue
+			}
+			name := "With" + n.Name
+			if _, ok := opts.Options[name]; ok {
+				continue
+			}
+			ret = append(ret, optionFunc(p, name, n.Name, f.Type, opts))
+		}
+	}
+	return ret, nil
+}
+
+// findOptionType returns the name of the type declared as func(*t).
+func findOptionType(p *loader.PackageInfo, t string) string {
+	ret := ""
+	for _, file := range p.Files {
+		ast.Inspect(file, func(n ast.Node) bool {
+			switch x := n.(type) {
+			case *ast.TypeSpec:
+				if y, ok := x.Type.(*ast.FuncType); ok && ret == "" {
+					if y.Results == nil && MethodParamTypes(y) == GetPointedType(t) {
+						ret = x.Name.Name
+					}
+				}
+			}
+			return true
+		})
+	}
+	return ret
+}
+
+// isOptionsCtor returns true if the last param of m is ...option.
+func isOptionsCtor(m *ast.FuncDecl, option string) bool {
+	if !MethodHasEllipse(m) {
+		return false
+	}
+	l := m.Type.Params.List
+	return ToString(l[len(l)-1].Type.(*ast.Ellipsis).Elt) == option
+}
+
+// optionFunc creates the option func name setting field of opts.Type.
+// The names of the param and of the receiver do not collide
+// with the identifiers of the field type nor the imports of p.
+func optionFunc(p *loader.PackageInfo, name, field string, t ast.Expr, opts *FunctionalOptions) *ast.FuncDecl {
+	namer := NewNamer(opts.Type, opts.Option)
+	namer.Reserve(importNames(p)...)
+	ast.Inspect(t, func(n ast.Node) bool {
+		if x, ok := n.(*ast.Ident); ok {
+			namer.Reserve(x.Name)
+		}
+		return true
+	})
+	v := namer.Name("v")
+	recv := namer.Name(strings.ToLower(opts.Type[:1]))
+	return &ast.FuncDecl{
+		Name: ast.NewIdent(name),
+		Type: &ast.FuncType{
+			Params: &ast.FieldList{List: []*ast.Field{
+				{Names: []*ast.Ident{ast.NewIdent(v)}, Type: cloneNode(t).(ast.Expr)},
+			}},
+			Results: &ast.FieldList{List: []*ast.Field{
+				{Type: ast.NewIdent(opts.Option)},
+			}},
+		},
+		Body: &ast.BlockStmt{List: []ast.Stmt{
+			&ast.ReturnStmt{Results: []ast.Expr{
+				&ast.FuncLit{
+					Type: &ast.FuncType{
+						Params: &ast.FieldList{List: []*ast.Field{
+							{
+								Names: []*ast.Ident{ast.NewIdent(recv)},
+								Type:  &ast.StarExpr{X: ast.NewIdent(opts.Type)},
+							},
+						}},
+					},
+					Body: &ast.BlockStmt{List: []ast.Stmt{
+						&ast.AssignStmt{
+							Lhs: []ast.Expr{&ast.SelectorExpr{X: ast.NewIdent(recv), Sel: ast.NewIdent(field)}},
+							Tok: token.ASSIGN,
+							Rhs: []ast.Expr{ast.NewIdent(v)},
+						},
+					}},
+				},
+			}},
+		}},
+	}
+}
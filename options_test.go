@@ -0,0 +1,89 @@
+package astutil
+
+import "testing"
+
+func TestFindFunctionalOptions(t *testing.T) {
+	prog := getProgramFromString(`
+type T struct{
+	Name string
+	Size int
+	hidden bool
+}
+type Option func(*T)
+
+func NewT(opts ...Option) *T { return nil }
+func NewTNoOpts() *T { return nil }
+
+func WithName(v string) Option { return nil }
+`)
+	pkg := prog.Package("thepackagename")
+	opts := FindFunctionalOptions(pkg, "T")
+	if opts == nil {
+		t.Fatal("functional options not found")
+	}
+	if want := "Option"; opts.Option != want {
+		t.Errorf("want %v got %v", want, opts.Option)
+	}
+	if l := len(opts.Ctors); l != 1 {
+		t.Errorf("want %v ctors got %v", 1, l)
+	}
+	if _, ok := opts.Options["WithName"]; !ok {
+		t.Errorf("option WithName not found")
+	}
+
+	funcs, err := GenerateOptions(pkg, "T")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l := len(funcs); l != 1 {
+		t.Fatalf("want %v generated options got %v", 1, l)
+	}
+	want := `func WithSize(v int) Option {
+	return func(t *T) {
+		t.Size = v
+	}
+}`
+	if got := Print(funcs[0]); want != got {
+		t.Errorf("want=\n%v\ngot=\n%v", want, got)
+	}
+}
+
+func TestFindFunctionalOptionsNotFound(t *testing.T) {
+	prog := getProgramFromString(`type T struct{}`)
+	pkg := prog.Package("thepackagename")
+	if opts := FindFunctionalOptions(pkg, "T"); opts != nil {
+		t.Errorf("want nil got %v", opts)
+	}
+	if _, err := GenerateOptions(pkg, "T"); err == nil {
+		t.Errorf("want an error")
+	}
+}
+
+func TestGenerateOptionsNames(t *testing.T) {
+	prog := getProgramFromString(`
+type V struct{
+	F func(v int)
+}
+type Option func(*V)
+`)
+	pkg := prog.Package("thepackagename")
+	funcs, err := GenerateOptions(pkg, "V")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l := len(funcs); l != 1 {
+		t.Fatalf("want %v generated options got %v", 1, l)
+	}
+	want := `func WithF(v1 func(v int)) Option {
+	return func(v2 *V) {
+		v2.F = v1
+	}
+}`
+	if got := Print(funcs[0]); want != got {
+		t.Errorf("want=\n%v\ngot=\n%v", want, got)
+	}
+	src := GetStruct(pkg, "V").Fields.List[0].Type
+	if got := funcs[0].Type.Params.List[0].Type; got == src || got.Pos() == src.Pos() {
+		t.Errorf("the param type must be a copy of the field type")
+	}
+}
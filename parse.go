@@ -0,0 +1,121 @@
+package astutil
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"strings"
+)
+
+// ParseExpr parses the expression s.
+func ParseExpr(s string) (ast.Expr, error) {
+	ret, err := parser.ParseExpr(s)
+	if err != nil {
+		return nil, snippetError(err, "", s)
+	}
+	return ret, nil
+}
+
+// ParseType parses the type expression s, such as map[string]*pkg.T.
+func ParseType(s string) (ast.Expr, error) {
+	f, err := parseSnippet("package p\nvar _ ", s, "", 0)
+	if err != nil {
+		return nil, err
+	}
+	d, ok := f.Decls[0].(*ast.GenDecl)
+	if !ok || len(f.Decls) != 1 || len(d.Specs) != 1 {
+		return nil, fmt.Errorf("snippet: %q is not a type expression", s)
+	}
+	v := d.Specs[0].(*ast.ValueSpec)
+	if v.Type == nil || len(v.Values) > 0 {
+		return nil, fmt.Errorf("snippet: %q is not a type expression", s)
+	}
+	return v.Type, nil
+}
+
+// ParseStmts parses a list of statements, comments are dropped.
+func ParseStmts(s string) ([]ast.Stmt, error) {
+	ret, _, err := parseStmts(s, 0)
+	return ret, err
+}
+
+// parseStmts parses a list of statements,
+// with parser.ParseComments the comments are returned.
+func parseStmts(s string, mode parser.Mode) ([]ast.Stmt, []*ast.CommentGroup, error) {
+	f, err := parseSnippet("package p\nfunc _() {\n", s, "\n}", mode)
+	if err != nil {
+		return nil, nil, err
+	}
+	return f.Decls[0].(*ast.FuncDecl).Body.List, f.Comments, nil
+}
+
+// ParseDecls parses a list of declarations, comments are attached to their declaration.
+func ParseDecls(s string) ([]ast.Decl, error) {
+	f, err := parseSnippet("package p\n", s, "", parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	return f.Decls, nil
+}
+
+// ParseDecl parses a single declaration.
+func ParseDecl(s string) (ast.Decl, error) {
+	ret, err := ParseDecls(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(ret) != 1 {
+		return nil, fmt.Errorf("snippet: want 1 declaration got %v", len(ret))
+	}
+	return ret[0], nil
+}
+
+// ParseFile parses the go source file s named name into fset, with its comments.
+func ParseFile(fset *token.FileSet, name, s string) (*ast.File, error) {
+	return parser.ParseFile(fset, name, s, parser.ParseComments)
+}
+
+// parseSnippet parses the snippet s wrapped into a file with prefix and suffix,
+// error positions are relative to the snippet.
+func parseSnippet(prefix, s, suffix string, mode parser.Mode) (*ast.File, error) {
+	src := prefix + s + suffix
+	f, err := parser.ParseFile(token.NewFileSet(), "", src, mode)
+	if err != nil {
+		return nil, snippetError(err, prefix, s)
+	}
+	return f, nil
+}
+
+// snippetError moves the positions of a parse error from the wrapped source to the snippet.
+// Errors located in the suffix are reported at the end of the snippet s.
+func snippetError(err error, prefix, s string) error {
+	list, ok := err.(scanner.ErrorList)
+	if !ok {
+		return err
+	}
+	lines := strings.Count(prefix, "\n")
+	col := len(prefix) - strings.LastIndex(prefix, "\n") - 1
+	end := token.Position{
+		Filename: "snippet",
+		Offset:   len(s),
+		Line:     strings.Count(s, "\n") + 1,
+		Column:   len(s) - strings.LastIndex(s, "\n"),
+	}
+	var ret scanner.ErrorList
+	for _, e := range list {
+		pos := e.Pos
+		pos.Filename = "snippet"
+		if pos.Line == lines+1 {
+			pos.Column -= col
+		}
+		pos.Line -= lines
+		pos.Offset -= len(prefix)
+		if pos.Offset > len(s) {
+			pos = end
+		}
+		ret.Add(pos, e.Msg)
+	}
+	return ret
+}
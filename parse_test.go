@@ -0,0 +1,125 @@
+package astutil
+
+import (
+	"go/ast"
+	"go/scanner"
+	"go/token"
+	"testing"
+)
+
+func TestParseExpr(t *testing.T) {
+	x, err := ParseExpr("a.b(c, d...)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := x.(*ast.CallExpr); !ok {
+		t.Errorf("want=*ast.CallExpr got=%T", x)
+	}
+	if want, got := "a.b(c, d...)", Print(x); want != got {
+		t.Errorf("want=%v got=%v", want, got)
+	}
+	if _, err := ParseExpr("a +"); err == nil {
+		t.Error("want an error")
+	}
+}
+
+func TestParseType(t *testing.T) {
+	for _, s := range []string{"int", "*pkg.T", "map[string][]*T", "func(int) error", "chan<- T", "[4]int", "T[int, string]"} {
+		x, err := ParseType(s)
+		if err != nil {
+			t.Errorf("%v: %v", s, err)
+			continue
+		}
+		if got := Print(x); s != got {
+			t.Errorf("want=%v got=%v", s, got)
+		}
+	}
+	for _, s := range []string{"map[", "int = 1", "int\nvar b int", ""} {
+		if _, err := ParseType(s); err == nil {
+			t.Errorf("%q: want an error", s)
+		}
+	}
+}
+
+func TestParseStmts(t *testing.T) {
+	stmts, err := ParseStmts("x := 1\nif x > 0 {\n\treturn\n}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stmts) != 2 {
+		t.Fatalf("want=2 statements got=%v", len(stmts))
+	}
+	if _, ok := stmts[1].(*ast.IfStmt); !ok {
+		t.Errorf("want=*ast.IfStmt got=%T", stmts[1])
+	}
+}
+
+func TestParseDecl(t *testing.T) {
+	d, err := ParseDecl("// F does.\nfunc F() {\n\tx()\n}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "// F does.\nfunc F() {\n\tx()\n}"
+	if got := Print(d); want != got {
+		t.Errorf("want=\n%v\ngot=\n%v", want, got)
+	}
+	decls, err := ParseDecls("type T struct{}\nvar v T")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(decls) != 2 {
+		t.Errorf("want=2 declarations got=%v", len(decls))
+	}
+	if _, err := ParseDecl("type T struct{}\nvar v T"); err == nil {
+		t.Error("want an error")
+	}
+}
+
+func TestParseFile(t *testing.T) {
+	fset := token.NewFileSet()
+	f, err := ParseFile(fset, "a.go", "package a\n\n// T is.\ntype T struct{}\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, got := "a", f.Name.Name; want != got {
+		t.Errorf("want=%v got=%v", want, got)
+	}
+	if want, got := 1, len(f.Comments); want != got {
+		t.Errorf("want=%v comments got=%v", want, got)
+	}
+	if want, got := "a.go", fset.Position(f.Pos()).Filename; want != got {
+		t.Errorf("want=%v got=%v", want, got)
+	}
+}
+
+func TestParseErrorPosition(t *testing.T) {
+	_, err := ParseStmts("x := 1\ny := )")
+	list, ok := err.(scanner.ErrorList)
+	if !ok || len(list) == 0 {
+		t.Fatalf("want a scanner.ErrorList got=%#v", err)
+	}
+	pos := list[0].Pos
+	if pos.Filename != "snippet" || pos.Line != 2 || pos.Column != 6 {
+		t.Errorf("want=snippet:2:6 got=%v", pos)
+	}
+
+	_, err = ParseType("map[string]]")
+	list, ok = err.(scanner.ErrorList)
+	if !ok || len(list) == 0 {
+		t.Fatalf("want a scanner.ErrorList got=%#v", err)
+	}
+	pos = list[0].Pos
+	if pos.Filename != "snippet" || pos.Line != 1 || pos.Column != 12 {
+		t.Errorf("want=snippet:1:12 got=%v", pos)
+	}
+
+	_, err = ParseStmts("x := 1\nif x {")
+	list, ok = err.(scanner.ErrorList)
+	if !ok || len(list) == 0 {
+		t.Fatalf("want a scanner.ErrorList got=%#v", err)
+	}
+	pos = list[0].Pos
+	if pos.Filename != "snippet" || pos.Line != 2 || pos.Column != 7 || pos.Offset != 13 {
+		t.Errorf("want=snippet:2:7 at offset 13 got=%v at offset %v", pos, pos.Offset)
+	}
+}
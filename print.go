@@ -0,0 +1,325 @@
+package astutil
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/tools/go/loader"
+)
+
+// Format formats the node x with the file set fset.
+// It returns an error if x is an invalid ast.
+func Format(fset *token.FileSet, x interface{}) (ret string, err error) {
+	if n, ok := x.(ast.Node); ok {
+		if err := checkNode(fset, n); err != nil {
+			return "", err
+		}
+		if doc := docOf(n); doc != nil && fset.File(doc.Pos()) == nil {
+			switch n.(type) {
+			case *ast.FuncDecl, *ast.GenDecl:
+				return formatBuiltDoc(fset, n, doc)
+			}
+		}
+	}
+	defer func() {
+		if e := recover(); e != nil {
+			ret, err = "", fmt.Errorf("invalid ast %T: %v", x, e)
+		}
+	}()
+	var b bytes.Buffer
+	if err := format.Node(&b, fset, x); err != nil {
+		return "", err
+	}
+	if _, ok := x.(*ast.File); ok {
+		if _, err := parser.ParseFile(token.NewFileSet(), "", b.Bytes(), 0); err != nil {
+			return "", fmt.Errorf("invalid ast: %v", err)
+		}
+	}
+	return b.String(), nil
+}
+
+// formatBuiltDoc formats a declaration whose doc is not positioned in fset,
+// such as a node created with BuildFunc or ParseDecl,
+// the printer can not place such comments.
+func formatBuiltDoc(fset *token.FileSet, n ast.Node, doc *ast.CommentGroup) (string, error) {
+	switch x := n.(type) {
+	case *ast.FuncDecl:
+		y := *x
+		y.Doc = nil
+		n = &y
+	case *ast.GenDecl:
+		y := *x
+		y.Doc = nil
+		n = &y
+	}
+	ret, err := Format(fset, n)
+	if err != nil {
+		return "", err
+	}
+	var b bytes.Buffer
+	for _, c := range doc.List {
+		b.WriteString(c.Text + "\n")
+	}
+	return b.String() + ret, nil
+}
+
+// FormatWithComments formats the node n of the file f with the file set fset,
+// the doc, the inline comments and the trailing comment of the last line of n are preserved.
+func FormatWithComments(fset *token.FileSet, f *ast.File, n ast.Node) (string, error) {
+	if x, ok := n.(*ast.File); ok {
+		return Format(fset, x)
+	}
+	if err := checkNode(fset, n); err != nil {
+		return "", err
+	}
+	comments, trailing := nodeComments(fset, f, n)
+	ret, err := Format(fset, &printer.CommentedNode{Node: n, Comments: comments})
+	if err != nil {
+		return "", err
+	}
+	// the printer drops the comments after the node,
+	// but without comments to print it prints the comment groups of the nodes,
+	// such as the line comment of a spec, terminated with a new line.
+	ret = strings.TrimSuffix(ret, "\n")
+	printed := map[*ast.CommentGroup]bool{}
+	if len(comments) == 0 {
+		ast.Inspect(n, func(x ast.Node) bool {
+			if c, ok := x.(*ast.CommentGroup); ok {
+				printed[c] = true
+			}
+			return true
+		})
+	}
+	for _, c := range trailing {
+		if !printed[c] {
+			for _, x := range c.List {
+				ret += " " + x.Text
+			}
+		}
+	}
+	return ret, nil
+}
+
+// nodeComments returns the comments of the file f attached to the node n,
+// its doc and the comments within n,
+// then the trailing comments on the line where n ends.
+func nodeComments(fset *token.FileSet, f *ast.File, n ast.Node) ([]*ast.CommentGroup, []*ast.CommentGroup) {
+	start := n.Pos()
+	if doc := docOf(n); doc != nil {
+		start = doc.Pos()
+	}
+	line := fset.Position(n.End()).Line
+	var comments, trailing []*ast.CommentGroup
+	for _, c := range f.Comments {
+		if c.Pos() < start {
+			continue
+		}
+		if c.End() <= n.End() {
+			comments = append(comments, c)
+		} else if fset.Position(c.Pos()).Line == line {
+			trailing = append(trailing, c)
+		}
+	}
+	return comments, trailing
+}
+
+// FormatNode formats the node n of the program prog,
+// the doc and inline comments of n are preserved.
+func FormatNode(prog *loader.Program, n ast.Node) (string, error) {
+	if f := FindFile(prog, n); f != nil {
+		return FormatWithComments(prog.Fset, f, n)
+	}
+	return Format(prog.Fset, n)
+}
+
+// FindFile returns the file of the program containing the node n, or nil.
+func FindFile(prog *loader.Program, n ast.Node) *ast.File {
+	for _, p := range prog.AllPackages {
+		for _, f := range p.Files {
+			if f.Pos() <= n.Pos() && n.End() <= f.End() {
+				return f
+			}
+		}
+	}
+	return nil
+}
+
+// PrintPkgFiles formats every file of the package, by file name.
+func PrintPkgFiles(fset *token.FileSet, p *loader.PackageInfo) (map[string]string, error) {
+	ret := map[string]string{}
+	for _, file := range p.Files {
+		x, err := Format(fset, file)
+		if err != nil {
+			return nil, err
+		}
+		ret[fset.Position(file.Package).Filename] = x
+	}
+	return ret, nil
+}
+
+// WritePkgFiles formats and writes every file of the package to the directory dir.
+// If dir is empty, the files are written in place.
+func WritePkgFiles(fset *token.FileSet, p *loader.PackageInfo, dir string) error {
+	files, err := PrintPkgFiles(fset, p)
+	if err != nil {
+		return err
+	}
+	if dir != "" {
+		if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+			return err
+		}
+	}
+	for name, src := range files {
+		if dir != "" {
+			name = filepath.Join(dir, filepath.Base(name))
+		}
+		if err := ioutil.WriteFile(name, []byte(src), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MergePkgFiles merges every file of the package into a single formatted file.
+// Imports are deduplicated, it fails if two files import different paths with the same name.
+func MergePkgFiles(fset *token.FileSet, p *loader.PackageInfo) (string, error) {
+	type importSpec struct{ name, path string }
+	var imports []importSpec
+	names := map[string]string{}
+	seen := map[importSpec]bool{}
+	var doc *ast.CommentGroup
+	var decls []string
+	for _, file := range p.Files {
+		if doc == nil {
+			doc = file.Doc
+		}
+		for _, i := range file.Imports {
+			x := importSpec{path: i.Path.Value}
+			if i.Name != nil {
+				x.name = i.Name.Name
+			}
+			if seen[x] {
+				continue
+			}
+			name := x.name
+			if name == "" {
+				name = path.Base(strings.Trim(x.path, `"`))
+			}
+			if name != "_" && name != "." {
+				if y, ok := names[name]; ok && y != x.path {
+					return "", fmt.Errorf("import name %q conflicts: %v and %v", name, y, x.path)
+				}
+				names[name] = x.path
+			}
+			seen[x] = true
+			imports = append(imports, x)
+		}
+		// prev is the end of the last printed node,
+		// the comments between two declarations are printed as they are.
+		prev := file.Name.End()
+		for _, decl := range file.Decls {
+			comments, trailing := nodeComments(fset, file, decl)
+			end := decl.End()
+			if len(trailing) > 0 {
+				end = trailing[len(trailing)-1].End()
+			}
+			start := decl.Pos()
+			if len(comments) > 0 && comments[0].Pos() < start {
+				start = comments[0].Pos()
+			}
+			if c := freeComments(file, prev, start); c != "" {
+				decls = append(decls, c)
+			}
+			prev = end
+			if x, ok := decl.(*ast.GenDecl); ok && x.Tok == token.IMPORT {
+				continue
+			}
+			d, err := FormatWithComments(fset, file, decl)
+			if err != nil {
+				return "", err
+			}
+			decls = append(decls, d)
+		}
+		f := fset.File(file.Pos())
+		if c := freeComments(file, prev, f.Pos(f.Size())); c != "" {
+			decls = append(decls, c)
+		}
+	}
+
+	var b bytes.Buffer
+	if doc != nil {
+		for _, c := range doc.List {
+			b.WriteString(c.Text + "\n")
+		}
+	}
+	fmt.Fprintf(&b, "package %v\n\n", p.Pkg.Name())
+	if len(imports) > 0 {
+		b.WriteString("import (\n")
+		for _, i := range imports {
+			fmt.Fprintf(&b, "%v %v\n", i.name, i.path)
+		}
+		b.WriteString(")\n\n")
+	}
+	b.WriteString(strings.Join(decls, "\n\n"))
+	ret, err := format.Source(b.Bytes())
+	return string(ret), err
+}
+
+// freeComments returns the text of the comments of the file f
+// which are between the positions start and end.
+func freeComments(f *ast.File, start, end token.Pos) string {
+	var ret []string
+	for _, c := range f.Comments {
+		if c.Pos() >= start && c.End() <= end {
+			for _, x := range c.List {
+				ret = append(ret, x.Text)
+			}
+			ret = append(ret, "")
+		}
+	}
+	return strings.TrimSpace(strings.Join(ret, "\n"))
+}
+
+// docOf returns the doc comment of n, or nil.
+func docOf(n ast.Node) *ast.CommentGroup {
+	switch x := n.(type) {
+	case *ast.FuncDecl:
+		return x.Doc
+	case *ast.GenDecl:
+		return x.Doc
+	case *ast.TypeSpec:
+		return x.Doc
+	case *ast.ValueSpec:
+		return x.Doc
+	case *ast.ImportSpec:
+		return x.Doc
+	case *ast.Field:
+		return x.Doc
+	}
+	return nil
+}
+
+// checkNode returns an error if n contains a bad node.
+func checkNode(fset *token.FileSet, n ast.Node) error {
+	var err error
+	ast.Inspect(n, func(n ast.Node) bool {
+		switch n.(type) {
+		case *ast.BadExpr, *ast.BadStmt, *ast.BadDecl:
+			if err == nil {
+				err = fmt.Errorf("invalid ast: %T at %v", n, fset.Position(n.Pos()))
+			}
+		}
+		return err == nil
+	})
+	return err
+}
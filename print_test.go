@@ -0,0 +1,224 @@
+package astutil
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"golang.org/x/tools/go/loader"
+)
+
+func TestFormatNode(t *testing.T) {
+	prog := getProgramFromString(`
+// F does.
+func F() {
+	// inline comment.
+	_ = 1 // trailing comment.
+}
+
+// G does.
+func G() {}
+`)
+	pkg := prog.Package("thepackagename")
+	f := pkg.Files[0].Decls[0].(*ast.FuncDecl)
+	got, err := FormatNode(prog, f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `// F does.
+func F() {
+	// inline comment.
+	_ = 1 // trailing comment.
+}`
+	if want != got {
+		t.Errorf("want=\n%v\ngot=\n%v", want, got)
+	}
+}
+
+func TestFormatNodeTrailingComment(t *testing.T) {
+	prog := getProgramFromString(`
+var A = 1 // trailing a
+
+func F() {} // trailing f
+
+// B is b.
+var B = 2 // trailing b
+
+// G does.
+func G() {} // trailing g
+`)
+	pkg := prog.Package("thepackagename")
+	decls := pkg.Files[0].Decls
+	for i, want := range []string{
+		"var A = 1 // trailing a",
+		"func F() {} // trailing f",
+		"// B is b.\nvar B = 2 // trailing b",
+		"// G does.\nfunc G() {} // trailing g",
+	} {
+		got, err := FormatNode(prog, decls[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want != got {
+			t.Errorf("want %v got %v", want, got)
+		}
+	}
+}
+
+func TestMergePkgFilesComments(t *testing.T) {
+	prog := getProgramFromFiles(map[string]string{
+		"a.go": `package thepackagename
+
+// A is a.
+var A = 1 // trailing a
+
+// free a.
+
+func F() {} // trailing f
+
+// end of a.
+`,
+		"b.go": `package thepackagename
+
+import "fmt"
+
+// free b.
+
+// B is b.
+var B = fmt.Sprint() // trailing b
+`,
+	})
+	pkg := prog.Package("thepackagename")
+	got, err := MergePkgFiles(prog.Fset, pkg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `package thepackagename
+
+import (
+	"fmt"
+)
+
+// A is a.
+var A = 1 // trailing a
+
+// free a.
+
+func F() {} // trailing f
+
+// end of a.
+
+// free b.
+
+// B is b.
+var B = fmt.Sprint() // trailing b
+`
+	if want != got {
+		t.Errorf("want=\n%v\ngot=\n%v", want, got)
+	}
+}
+
+func TestFormatInvalid(t *testing.T) {
+	f := &ast.FuncDecl{
+		Name: ast.NewIdent("F"),
+		Type: &ast.FuncType{Params: &ast.FieldList{}},
+		Body: &ast.BlockStmt{List: []ast.Stmt{&ast.BadStmt{}}},
+	}
+	if _, err := Format(token.NewFileSet(), f); err == nil {
+		t.Errorf("want an error")
+	}
+	if got := Print(f); got != "" {
+		t.Errorf("want empty string got %q", got)
+	}
+}
+
+func TestPrintPkgFiles(t *testing.T) {
+	testFiles := map[string]string{
+		"a.go": "// Package thepackagename is a package.\npackage thepackagename\n\nimport \"fmt\"\n\n// A is a.\nvar A = fmt.Sprint()\n",
+		"b.go": "package thepackagename\n\nimport (\n\t\"fmt\"\n\t\"strings\"\n)\n\n// B is b.\nvar B = fmt.Sprint(strings.TrimSpace(\"\"))\n",
+	}
+	prog := getProgramFromFiles(testFiles)
+	pkg := prog.Package("thepackagename")
+	files, err := PrintPkgFiles(prog.Fset, pkg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("want %v files got %v", 2, len(files))
+	}
+	for name, src := range files {
+		if want := testFiles[filepath.Base(name)]; want != src {
+			t.Errorf("%v: want=\n%v\ngot=\n%v", name, want, src)
+		}
+	}
+
+	got, err := MergePkgFiles(prog.Fset, pkg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `// Package thepackagename is a package.
+package thepackagename
+
+import (
+	"fmt"
+	"strings"
+)
+
+// A is a.
+var A = fmt.Sprint()
+
+// B is b.
+var B = fmt.Sprint(strings.TrimSpace(""))
+`
+	if want != got {
+		t.Errorf("want=\n%v\ngot=\n%v", want, got)
+	}
+
+	dir, err := ioutil.TempDir("", "astutil")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := WritePkgFiles(prog.Fset, pkg, dir); err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range testFiles {
+		got, err := ioutil.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want != string(got) {
+			t.Errorf("%v: want=\n%v\ngot=\n%s", name, want, got)
+		}
+	}
+}
+
+func getProgramFromFiles(files map[string]string) *loader.Program {
+	var conf loader.Config
+	conf.ParserMode = parser.ParseComments
+	conf.AllowErrors = true
+	var names []string
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var parsed []*ast.File
+	for _, name := range names {
+		file, err := conf.ParseFile(name, files[name])
+		if err != nil {
+			panic(err)
+		}
+		parsed = append(parsed, file)
+	}
+	conf.CreateFromFiles("thepackagename", parsed...)
+	prog, err := conf.Load()
+	if err != nil {
+		panic(err)
+	}
+	return prog
+}
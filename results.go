@@ -0,0 +1,64 @@
+package astutil
+
+import (
+	"go/ast"
+	"go/types"
+
+	"golang.org/x/tools/go/loader"
+)
+
+var errorType = types.Universe.Lookup("error").Type()
+
+// Result describes a result of a func signature.
+type Result struct {
+	*Param
+	Index  int
+	IsLast bool
+	// IsPointer is true for a pointer type.
+	IsPointer bool
+	// IsError is true for the predeclared error interface.
+	IsError bool
+	// ImplementsError is true for a type implementing the error interface.
+	ImplementsError bool
+}
+
+// GetResults returns the results of m, a func like node supported by GetFunc.
+// If p is nil, the results are inspected using their type expressions only,
+// otherwise the type information of p is used, so that a locally declared error type,
+// or a type implementing error are correctly reported.
+func GetResults(p *loader.PackageInfo, m ast.Node) []*Result {
+	s := GetSignature(m)
+	if p != nil && funcOf(m).Signature == nil {
+		s.Resolve(p)
+	}
+	var ret []*Result
+	for i, x := range s.Results {
+		r := &Result{
+			Param:  x,
+			Index:  i,
+			IsLast: i == len(s.Results)-1,
+		}
+		if x.T != nil {
+			_, r.IsPointer = x.T.Underlying().(*types.Pointer)
+			r.IsError = types.Identical(x.T, errorType)
+			r.ImplementsError = types.Implements(x.T, errorType.Underlying().(*types.Interface))
+		} else {
+			_, r.IsPointer = x.Type.(*ast.StarExpr)
+			if y, ok := x.Type.(*ast.Ident); ok {
+				r.IsError = y.Name == "error"
+			}
+			r.ImplementsError = r.IsError
+		}
+		ret = append(ret, r)
+	}
+	return ret
+}
+
+// LastResult returns the last result of m, or nil.
+func LastResult(p *loader.PackageInfo, m ast.Node) *Result {
+	r := GetResults(p, m)
+	if len(r) == 0 {
+		return nil
+	}
+	return r[len(r)-1]
+}
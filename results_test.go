@@ -0,0 +1,79 @@
+package astutil
+
+import (
+	"go/ast"
+	"testing"
+)
+
+func TestGetResults(t *testing.T) {
+	prog := getProgramFromString(`
+type MyErr struct{}
+func (e *MyErr) Error() string { return "" }
+
+type P *MyErr
+
+func F() (*MyErr, P, error) { return nil, nil, nil }
+`)
+	pkg := prog.Package("thepackagename")
+	var f *ast.FuncDecl
+	for _, d := range pkg.Files[0].Decls {
+		if x, ok := d.(*ast.FuncDecl); ok && x.Name.Name == "F" {
+			f = x
+		}
+	}
+	r := GetResults(pkg, f)
+	if len(r) != 3 {
+		t.Fatalf("want %v results got %v", 3, len(r))
+	}
+	tests := []struct {
+		r               *Result
+		isPointer       bool
+		isError         bool
+		implementsError bool
+		isLast          bool
+	}{
+		{r[0], true, false, true, false},
+		{r[1], true, false, false, false},
+		{r[2], false, true, true, true},
+	}
+	for i, test := range tests {
+		if test.r.IsPointer != test.isPointer {
+			t.Errorf("%v: IsPointer want %v got %v", i, test.isPointer, test.r.IsPointer)
+		}
+		if test.r.IsError != test.isError {
+			t.Errorf("%v: IsError want %v got %v", i, test.isError, test.r.IsError)
+		}
+		if test.r.ImplementsError != test.implementsError {
+			t.Errorf("%v: ImplementsError want %v got %v", i, test.implementsError, test.r.ImplementsError)
+		}
+		if test.r.IsLast != test.isLast {
+			t.Errorf("%v: IsLast want %v got %v", i, test.isLast, test.r.IsLast)
+		}
+	}
+}
+
+func TestGetResultsShadowedError(t *testing.T) {
+	prog := getProgramFromString(`
+type error struct{}
+
+func F() error { return error{} }
+`)
+	pkg := prog.Package("thepackagename")
+	f := pkg.Files[0].Decls[1].(*ast.FuncDecl)
+	r := LastResult(pkg, f)
+	if r.IsError || r.ImplementsError {
+		t.Errorf("a local error type must not be reported as the error interface")
+	}
+}
+
+func TestLastResult(t *testing.T) {
+	y := getFuncDecl(`func t() (*y, error) {}`)
+	r := LastResult(nil, y)
+	if r == nil || !r.IsError || r.Index != 1 {
+		t.Errorf("wrong last result %v", r)
+	}
+	y = getFuncDecl(`func t() {}`)
+	if r := LastResult(nil, y); r != nil {
+		t.Errorf("want nil got %v", r)
+	}
+}
@@ -0,0 +1,252 @@
+package astutil
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/loader"
+)
+
+// Param is a receiver, a parameter or a result of a func signature.
+type Param struct {
+	// Name is empty for unnamed results.
+	Name string
+	// Type is the type expression, an *ast.Ellipsis for a variadic param.
+	Type ast.Expr
+	// TypeString is the printed type expression.
+	TypeString string
+	Variadic   bool
+	// T is the type checked type, it is nil unless the signature is resolved.
+	T types.Type
+}
+
+// String returns the param as a declaration, name type.
+func (p *Param) String() string {
+	if p.Name == "" {
+		return p.TypeString
+	}
+	return p.Name + " " + p.TypeString
+}
+
+// Signature is the structured signature of a func.
+type Signature struct {
+	// Recv is nil for a func.
+	Recv    *Param
+	Params  []*Param
+	Results []*Param
+}
+
+// GetSignature returns the signature of m, a func like node supported by GetFunc.
+// The types of the params are set when m is the Func of a *types.Func or a *types.Signature.
+// The signature of an unsupported node is empty.
+func GetSignature(m ast.Node) *Signature {
+	f := funcOf(m)
+	ret := NewSignature(f.Recv, f.Type)
+	if s := f.Signature; s != nil {
+		if s.Recv() != nil && ret.Recv != nil {
+			ret.Recv.T = s.Recv().Type()
+		}
+		for i, p := range ret.Params {
+			p.T = s.Params().At(i).Type()
+		}
+		for i, p := range ret.Results {
+			p.T = s.Results().At(i).Type()
+		}
+	}
+	return ret
+}
+
+// NewSignature creates the signature of a func type with an optional receiver.
+// Grouped params are expanded, one Param per name.
+// Unnamed and blank params are given a name argN,
+// where N is the position of the param, unnamed results are left untouched.
+func NewSignature(recv *ast.FieldList, t *ast.FuncType) *Signature {
+	ret := &Signature{
+		Params:  newParams(t.Params),
+		Results: newParams(t.Results),
+	}
+	if r := newParams(recv); len(r) > 0 {
+		ret.Recv = r[0]
+	}
+	ret.nameParams()
+	return ret
+}
+
+// nameParams gives a name to the unnamed params not colliding with other names.
+func (s *Signature) nameParams() {
+	used := map[string]bool{}
+	for _, p := range s.all() {
+		used[p.Name] = true
+	}
+	for i, p := range s.Params {
+		if p.Name != "" && p.Name != "_" {
+			continue
+		}
+		name := fmt.Sprintf("arg%v", i)
+		for used[name] {
+			name += "_"
+		}
+		used[name] = true
+		p.Name = name
+	}
+}
+
+func newParams(l *ast.FieldList) []*Param {
+	var ret []*Param
+	if l == nil {
+		return ret
+	}
+	for _, f := range l.List {
+		_, variadic := f.Type.(*ast.Ellipsis)
+		typeString := ToString(f.Type)
+		if len(f.Names) == 0 {
+			ret = append(ret, &Param{Type: f.Type, TypeString: typeString, Variadic: variadic})
+		}
+		for _, n := range f.Names {
+			ret = append(ret, &Param{Name: n.Name, Type: f.Type, TypeString: typeString, Variadic: variadic})
+		}
+	}
+	return ret
+}
+
+// Resolve the types.Type of each param of the signature with the type info of p.
+func (s *Signature) Resolve(p *loader.PackageInfo) *Signature {
+	for _, x := range s.all() {
+		if x.Variadic {
+			if t := p.TypeOf(x.Type.(*ast.Ellipsis).Elt); t != nil {
+				x.T = types.NewSlice(t)
+			}
+		} else {
+			x.T = p.TypeOf(x.Type)
+		}
+	}
+	return s
+}
+
+func (s *Signature) all() []*Param {
+	var ret []*Param
+	if s.Recv != nil {
+		ret = append(ret, s.Recv)
+	}
+	ret = append(ret, s.Params...)
+	return append(ret, s.Results...)
+}
+
+// HasEllipse returns true if last param is variadic.
+func (s *Signature) HasEllipse() bool {
+	return len(s.Params) > 0 && s.Params[len(s.Params)-1].Variadic
+}
+
+// ParamNames returns the names of the params.
+func (s *Signature) ParamNames() []string {
+	return paramNames(s.Params)
+}
+
+// ParamTypes returns the types of the params.
+func (s *Signature) ParamTypes() []string {
+	return paramTypes(s.Params)
+}
+
+// ResultNames returns the names of the results.
+func (s *Signature) ResultNames() []string {
+	return paramNames(s.Results)
+}
+
+// ResultTypes returns the types of the results.
+func (s *Signature) ResultTypes() []string {
+	return paramTypes(s.Results)
+}
+
+// ParamsString returns the in signature, a string, b ...int.
+func (s *Signature) ParamsString() string {
+	return paramsString(s.Params)
+}
+
+// ResultsString returns the out signature, int or (a int, err error).
+func (s *Signature) ResultsString() string {
+	ret := paramsString(s.Results)
+	if len(s.Results) > 1 || (len(s.Results) == 1 && s.Results[0].Name != "") {
+		ret = "(" + ret + ")"
+	}
+	return ret
+}
+
+// Invokation returns the param names as an invokation, a, b.
+// If withEllipse is true, the variadic param gets the ellipse token.
+func (s *Signature) Invokation(withEllipse bool) string {
+	ret := s.ParamNames()
+	if withEllipse && s.HasEllipse() {
+		ret[len(ret)-1] += "..."
+	}
+	return strings.Join(ret, ", ")
+}
+
+// ParamsToProps returns the params as a property list, one per line.
+// A variadic param is turned into a slice.
+func (s *Signature) ParamsToProps() string {
+	var ret []string
+	for _, p := range s.Params {
+		t := p.TypeString
+		if p.Variadic {
+			t = "[]" + ToString(p.Type.(*ast.Ellipsis).Elt)
+		}
+		ret = append(ret, p.Name+" "+t)
+	}
+	return strings.Join(ret, "\n")
+}
+
+// String returns the func type, func(a string) (int, error).
+func (s *Signature) String() string {
+	ret := "func(" + s.ParamsString() + ")"
+	if r := s.ResultsString(); r != "" {
+		ret += " " + r
+	}
+	return ret
+}
+
+// FuncType returns the func type of the signature, params are always named.
+func (s *Signature) FuncType() *ast.FuncType {
+	ret := &ast.FuncType{Params: fieldList(s.Params)}
+	if len(s.Results) > 0 {
+		ret.Results = fieldList(s.Results)
+	}
+	return ret
+}
+
+func fieldList(l []*Param) *ast.FieldList {
+	ret := &ast.FieldList{}
+	for _, p := range l {
+		f := &ast.Field{Type: p.Type}
+		if p.Name != "" {
+			f.Names = []*ast.Ident{ast.NewIdent(p.Name)}
+		}
+		ret.List = append(ret.List, f)
+	}
+	return ret
+}
+
+func paramNames(l []*Param) []string {
+	var ret []string
+	for _, p := range l {
+		ret = append(ret, p.Name)
+	}
+	return ret
+}
+
+func paramTypes(l []*Param) []string {
+	var ret []string
+	for _, p := range l {
+		ret = append(ret, p.TypeString)
+	}
+	return ret
+}
+
+func paramsString(l []*Param) string {
+	var ret []string
+	for _, p := range l {
+		ret = append(ret, p.String())
+	}
+	return strings.Join(ret, ", ")
+}
@@ -0,0 +1,70 @@
+package astutil
+
+import (
+	"go/ast"
+	"go/types"
+	"testing"
+)
+
+func TestGetSignature(t *testing.T) {
+	y := getFuncDecl(`func (r *T) t(a, b string, f func(x, y int) error, c ...int) (n int, err error) {}`)
+	s := GetSignature(y)
+	if s.Recv == nil || s.Recv.Name != "r" || s.Recv.TypeString != "*T" {
+		t.Errorf("wrong receiver %v", s.Recv)
+	}
+	if l := len(s.Params); l != 4 {
+		t.Fatalf("want %v params got %v", 4, l)
+	}
+	if got := s.Params[2].TypeString; got != "func(x, y int) error" {
+		t.Errorf("want %v got %v", "func(x, y int) error", got)
+	}
+	if !s.Params[3].Variadic {
+		t.Errorf("last param must be variadic")
+	}
+	want := "a string, b string, f func(x, y int) error, c ...int"
+	if got := s.ParamsString(); want != got {
+		t.Errorf("want %v got %v", want, got)
+	}
+	want = "(n int, err error)"
+	if got := s.ResultsString(); want != got {
+		t.Errorf("want %v got %v", want, got)
+	}
+	want = "a, b, f, c..."
+	if got := s.Invokation(true); want != got {
+		t.Errorf("want %v got %v", want, got)
+	}
+	want = "a string\nb string\nf func(x, y int) error\nc []int"
+	if got := s.ParamsToProps(); want != got {
+		t.Errorf("want %v got %v", want, got)
+	}
+	want = "func(a string, b string, f func(x, y int) error, c ...int) (n int, err error)"
+	if got := s.String(); want != got {
+		t.Errorf("want %v got %v", want, got)
+	}
+}
+
+func TestSignatureResolve(t *testing.T) {
+	prog := getProgramFromString(`func F(a string, b ...int) error { return nil }`)
+	pkg := prog.Package("thepackagename")
+	s := GetSignature(pkg.Files[0].Decls[0].(*ast.FuncDecl)).Resolve(pkg)
+	if got := types.TypeString(s.Params[1].T, nil); got != "[]int" {
+		t.Errorf("want %v got %v", "[]int", got)
+	}
+	if got := types.TypeString(s.Results[0].T, nil); got != "error" {
+		t.Errorf("want %v got %v", "error", got)
+	}
+}
+
+func TestGetSignatureImportIdentifiers(t *testing.T) {
+	y := getFuncDecl(`func t(f func(a, b int), c []*other.T) (*third.T, error) {}`)
+	want := []string{"other", "third"}
+	got := GetSignatureImportIdentifiers(y)
+	if len(want) != len(got) {
+		t.Fatalf("want %v got %v", want, got)
+	}
+	for i := range want {
+		if want[i] != got[i] {
+			t.Errorf("want %v got %v", want[i], got[i])
+		}
+	}
+}
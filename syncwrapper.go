@@ -0,0 +1,79 @@
+package astutil
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+
+	"golang.org/x/tools/go/loader"
+)
+
+// SyncWrapperOptions configures GenerateSyncWrapper.
+type SyncWrapperOptions struct {
+	// Name of the wrapper type, it defaults to the type name prefixed with Sync.
+	Name string
+	// ReadOnly is the annotation of the methods guarded by a read lock,
+	// it defaults to @readonly.
+	ReadOnly string
+}
+
+// GenerateSyncWrapper generates the source of a file declaring
+// a mutex guarded wrapper of the type t.
+// Each exported method of t is re-exposed behind a sync.RWMutex,
+// methods having the ReadOnly annotation acquire a read lock.
+func GenerateSyncWrapper(p *loader.PackageInfo, t string, o SyncWrapperOptions) ([]byte, error) {
+	t = GetUnpointedType(t)
+	if !HasStruct(p, t) {
+		return nil, fmt.Errorf("struct %q not found", t)
+	}
+	if o.Name == "" {
+		o.Name = "Sync" + t
+	}
+	if o.ReadOnly == "" {
+		o.ReadOnly = "@readonly"
+	}
+
+	imports := NewImports()
+	if err := imports.Add("", "sync"); err != nil {
+		return nil, err
+	}
+	var body bytes.Buffer
+	for _, m := range FindMethods(p)[t] {
+		name := MethodName(m)
+		if !IsExported(name) {
+			continue
+		}
+		if err := imports.AddSignature(p, m); err != nil {
+			return nil, err
+		}
+		recv := NewFuncNamer(p, m).Name("s")
+		lock, unlock := "Lock", "Unlock"
+		if HasAnnotation(m.Doc.Text(), o.ReadOnly) {
+			lock, unlock = "RLock", "RUnlock"
+		}
+		w := GenerateWrapper(m, WrapperOptions{
+			Recv: &ast.Field{
+				Names: []*ast.Ident{ast.NewIdent(recv)},
+				Type:  &ast.StarExpr{X: ast.NewIdent(o.Name)},
+			},
+			Target: &ast.SelectorExpr{X: ast.NewIdent(recv), Sel: ast.NewIdent("value")},
+			Before: func(s *Signature, results []string, n *Namer) []ast.Stmt {
+				ret, _ := ParseStmts(fmt.Sprintf("%v.mu.%v()\ndefer %v.mu.%v()", recv, lock, recv, unlock))
+				return ret
+			},
+		})
+		fmt.Fprintf(&body, "\n// %v is a thread safe wrapper of %v.%v.\n", name, ReceiverType(m), name)
+		body.WriteString(Print(w) + "\n")
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "package %v\n\n", p.Pkg.Name())
+	b.WriteString(imports.String())
+	fmt.Fprintf(&b, "\n// %v is a thread safe wrapper of %v.\n", o.Name, t)
+	fmt.Fprintf(&b, "type %v struct {\nmu sync.RWMutex\nvalue *%v\n}\n", o.Name, t)
+	fmt.Fprintf(&b, "\n// New%v returns a thread safe wrapper of v.\n", o.Name)
+	fmt.Fprintf(&b, "func New%v(v *%v) *%v {\nreturn &%v{value: v}\n}\n", o.Name, t, o.Name, o.Name)
+	b.Write(body.Bytes())
+	return format.Source(b.Bytes())
+}
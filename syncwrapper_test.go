@@ -0,0 +1,86 @@
+package astutil
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateSyncWrapper(t *testing.T) {
+	src := `
+type T struct{}
+
+// Get gets.
+// @readonly
+func (t T) Get(s string) (string, error) { return s, nil }
+
+func (t *T) Set(s ...string) {}
+
+func (t *T) hidden() {}
+`
+	prog := getProgramFromString(src)
+	pkg := prog.Package("thepackagename")
+	got, err := GenerateSyncWrapper(pkg, "T", SyncWrapperOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `package thepackagename
+
+import (
+	"sync"
+)
+
+// SyncT is a thread safe wrapper of T.
+type SyncT struct {
+	mu    sync.RWMutex
+	value *T
+}
+
+// NewSyncT returns a thread safe wrapper of v.
+func NewSyncT(v *T) *SyncT {
+	return &SyncT{value: v}
+}
+
+// Get is a thread safe wrapper of T.Get.
+func (s1 *SyncT) Get(s string) (string, error) {
+	s1.mu.RLock()
+	defer s1.mu.RUnlock()
+	retVar0, retVar1 := s1.value.Get(s)
+	return retVar0, retVar1
+}
+
+// Set is a thread safe wrapper of T.Set.
+func (s1 *SyncT) Set(s ...string) {
+	s1.mu.Lock()
+	defer s1.mu.Unlock()
+	s1.value.Set(s...)
+}
+`
+	if want != string(got) {
+		t.Errorf("want=\n%v\ngot=\n%v", want, string(got))
+	}
+
+	assertCompiles(t, prog, got)
+}
+
+func TestGenerateSyncWrapperCompiles(t *testing.T) {
+	prog := getProgramFromString(`
+import "io"
+
+type T struct{}
+
+func (t *T) Name() (s string) { return "" }
+
+func (t *T) Copy(w io.Writer, s1 string) (n int, err error) { return 0, nil }
+
+var _ io.Reader
+`)
+	pkg := prog.Package("thepackagename")
+	got, err := GenerateSyncWrapper(pkg, "T", SyncWrapperOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(got), "func (s1 *SyncT) Name() (s string) {") {
+		t.Errorf("unexpected receiver name\n%s", got)
+	}
+	assertCompiles(t, prog, got)
+}
@@ -0,0 +1,190 @@
+package astutil
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/scanner"
+	"go/token"
+	"strconv"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+// FuncMap returns the astutil functions usable within a text/template,
+// they are named after their go func.
+func FuncMap() template.FuncMap {
+	return template.FuncMap{
+		"IsExported":                    IsExported,
+		"MethodName":                    MethodName,
+		"MethodReturnPointer":           MethodReturnPointer,
+		"MethodReturnError":             MethodReturnError,
+		"MethodReturnTypes":             MethodReturnTypes,
+		"MethodReturnNames":             MethodReturnNames,
+		"MethodReturnNamesNormalized":   MethodReturnNamesNormalized,
+		"MethodReturnVars":              MethodReturnVars,
+		"MethodParamNames":              MethodParamNames,
+		"MethodParamTypes":              MethodParamTypes,
+		"MethodParamNamesInvokation":    MethodParamNamesInvokation,
+		"MethodHasEllipse":              MethodHasEllipse,
+		"MethodParams":                  MethodParams,
+		"MethodParamsToProps":           MethodParamsToProps,
+		"GetSignature":                  GetSignature,
+		"GetSignatureImportIdentifiers": GetSignatureImportIdentifiers,
+		"GetPkgID":                      GetPkgID,
+		"ReceiverName":                  ReceiverName,
+		"ReceiverType":                  ReceiverType,
+		"IsAPointedType":                IsAPointedType,
+		"GetUnpointedType":              GetUnpointedType,
+		"GetPointedType":                GetPointedType,
+		"IsASlicedType":                 IsASlicedType,
+		"GetUnslicedType":               GetUnslicedType,
+		"GetTypeToStructInit":           GetTypeToStructInit,
+		"IsBasic":                       IsBasic,
+		"IsArrayType":                   IsArrayType,
+		"IsStarType":                    IsStarType,
+		"StructProps":                   StructProps,
+		"GetAnnotations":                GetAnnotations,
+		"HasAnnotation":                 HasAnnotation,
+		"Print":                         Print,
+		"ToString":                      ToString,
+		"FindTypes":                     FindTypes,
+		"FindStruct":                    FindStruct,
+		"GetStruct":                     GetStruct,
+		"FindMethods":                   FindMethods,
+		"HasMethod":                     HasMethod,
+		"HasStruct":                     HasStruct,
+		"GetImportPath":                 GetImportPath,
+		"GetComment":                    GetComment,
+	}
+}
+
+// NewTemplate creates a template named name with the FuncMap functions.
+func NewTemplate(name string) *template.Template {
+	return template.New(name).Funcs(FuncMap())
+}
+
+// Render executes the template tpl with data, such as a declaration of a loaded program,
+// then formats the output as a go source.
+// The positions of the format errors are the template lines which produced the faulty lines.
+func Render(tpl *template.Template, data interface{}) ([]byte, error) {
+	c, err := tpl.Clone()
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range c.Templates() {
+		if t.Tree != nil && t.Tree.Root != nil {
+			t.Tree = t.Tree.Copy()
+			markLines(t.Tree)
+		}
+	}
+	var b bytes.Buffer
+	if err := c.Execute(&b, data); err != nil {
+		return nil, err
+	}
+	src, locs := unmarkLines(b.Bytes())
+	ret, err := format.Source(src)
+	if err != nil {
+		return nil, templateError(err, locs)
+	}
+	return ret, nil
+}
+
+// lineMarker delimits the template location written in the output of a marked template.
+const lineMarker = "\x00"
+
+// markLines inserts the template location of every line of the text nodes of tree.
+func markLines(tree *parse.Tree) {
+	var walk func(l *parse.ListNode)
+	walk = func(l *parse.ListNode) {
+		if l == nil {
+			return
+		}
+		for _, n := range l.Nodes {
+			switch n := n.(type) {
+			case *parse.TextNode:
+				var text []byte
+				for i, x := range bytes.Split(n.Text, []byte("\n")) {
+					if i > 0 {
+						text = append(text, '\n')
+						text = append(text, lineMark(tree, n, i)...)
+					}
+					text = append(text, x...)
+				}
+				n.Text = text
+			case *parse.IfNode:
+				walk(n.List)
+				walk(n.ElseList)
+			case *parse.RangeNode:
+				walk(n.List)
+				walk(n.ElseList)
+			case *parse.WithNode:
+				walk(n.List)
+				walk(n.ElseList)
+			}
+		}
+	}
+	walk(tree.Root)
+	start := &parse.TextNode{NodeType: parse.NodeText, Text: lineMark(tree, tree.Root, 0)}
+	tree.Root.Nodes = append([]parse.Node{start}, tree.Root.Nodes...)
+}
+
+// lineMark returns the marker of the template location
+// of the line at offset lines from the node n.
+func lineMark(tree *parse.Tree, n parse.Node, offset int) []byte {
+	loc, _ := tree.ErrorContext(n)
+	loc = loc[:strings.LastIndex(loc, ":")]
+	i := strings.LastIndex(loc, ":")
+	line, _ := strconv.Atoi(loc[i+1:])
+	return []byte(lineMarker + loc[:i] + ":" + strconv.Itoa(line+offset) + lineMarker)
+}
+
+// unmarkLines removes the markers of the output of a marked template,
+// it returns the template location of each line of the output.
+func unmarkLines(out []byte) ([]byte, []string) {
+	var src []byte
+	var locs []string
+	cur := ""
+	start := true
+	for i, x := range bytes.Split(out, []byte(lineMarker)) {
+		if i%2 == 1 {
+			cur = string(x)
+			continue
+		}
+		for _, c := range x {
+			if start {
+				locs = append(locs, cur)
+				start = false
+			}
+			src = append(src, c)
+			start = c == '\n'
+		}
+	}
+	return src, append(locs, cur)
+}
+
+// templateError moves the positions of a format error
+// from the output of a template to the template locations.
+func templateError(err error, locs []string) error {
+	list, ok := err.(scanner.ErrorList)
+	if !ok {
+		return err
+	}
+	var ret scanner.ErrorList
+	for _, e := range list {
+		pos := e.Pos
+		if pos.Line > 0 {
+			loc := locs[len(locs)-1]
+			if pos.Line <= len(locs) {
+				loc = locs[pos.Line-1]
+			}
+			if i := strings.LastIndex(loc, ":"); i > -1 {
+				line, _ := strconv.Atoi(loc[i+1:])
+				pos = token.Position{Filename: loc[:i], Line: line}
+			}
+		}
+		ret.Add(pos, fmt.Sprintf("%v (output line %v)", e.Msg, e.Pos.Line))
+	}
+	return ret
+}
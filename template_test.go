@@ -0,0 +1,95 @@
+package astutil
+
+import (
+	"go/ast"
+	"go/scanner"
+	"strings"
+	"testing"
+	"text/template"
+)
+
+func TestRender(t *testing.T) {
+	m := getFuncDecl(`func (t *Tomate) Hello(a string, b ...int) (int, error) { return 0, nil }`)
+	tpl := template.Must(NewTemplate("wrapper").Funcs(template.FuncMap{"join": strings.Join}).Parse(`package p
+
+// {{MethodName .}} wraps {{ReceiverType .}}.
+func (w *Wrapper) {{MethodName .}}({{MethodParams .}}) ({{join (MethodReturnTypes .) ", "}}) {
+	{{if MethodReturnError . -}}
+	return w.{{ReceiverName .}}.{{MethodName .}}({{MethodParamNamesInvokation . true}})
+	{{- end}}
+}
+`))
+	got, err := Render(tpl, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `package p
+
+// Hello wraps Tomate.
+func (w *Wrapper) Hello(a string, b ...int) (int, error) {
+	return w.t.Hello(a, b...)
+}
+`
+	if want != string(got) {
+		t.Errorf("want %v got %v", want, string(got))
+	}
+}
+
+func TestRenderStructProps(t *testing.T) {
+	s := getStructDecl(`type T struct {
+	Name string
+	Tags []string
+}`)
+	tpl := template.Must(NewTemplate("props").Parse(`package p
+
+func (t T) Props() []string {
+	return []string{ {{- range StructProps .}}"{{.name}} {{GetUnslicedType .type}}",{{end -}} }
+}
+`))
+	got, err := Render(tpl, s.Type.(*ast.StructType))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `package p
+
+func (t T) Props() []string {
+	return []string{"Name string", "Tags string"}
+}
+`
+	if want != string(got) {
+		t.Errorf("want %v got %v", want, string(got))
+	}
+}
+
+func TestRenderError(t *testing.T) {
+	tpl := template.Must(NewTemplate("main").Parse(`package p
+{{range .}}
+func {{.}}() {
+	{{template "body" .}}
+}
+{{end}}
+{{define "body"}}
+	x := 1
+	y := )
+{{end}}`))
+	_, err := Render(tpl, []string{"A", "B"})
+	list, ok := err.(scanner.ErrorList)
+	if !ok || len(list) == 0 {
+		t.Fatalf("want a scanner.ErrorList got %#v", err)
+	}
+	pos := list[0].Pos
+	if pos.Filename != "main" || pos.Line != 9 {
+		t.Errorf("want %v got %v", "main:9", pos)
+	}
+
+	tpl = template.Must(NewTemplate("main").Parse("package p\n\nfunc {{.}}( {\n}\n"))
+	_, err = Render(tpl, "A")
+	list, ok = err.(scanner.ErrorList)
+	if !ok || len(list) == 0 {
+		t.Fatalf("want a scanner.ErrorList got %#v", err)
+	}
+	pos = list[0].Pos
+	if pos.Filename != "main" || pos.Line != 3 {
+		t.Errorf("want %v got %v", "main:3", pos)
+	}
+}
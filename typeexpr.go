@@ -0,0 +1,257 @@
+package astutil
+
+import (
+	"go/ast"
+	"go/types"
+	"strings"
+)
+
+// TypeKind is the kind of a TypeExpr.
+type TypeKind int
+
+// The kinds of TypeExpr.
+const (
+	// KindName is a named type, T or pkg.T.
+	KindName TypeKind = iota
+	// KindPointer is *Elem.
+	KindPointer
+	// KindSlice is []Elem.
+	KindSlice
+	// KindArray is [Len]Elem.
+	KindArray
+	// KindMap is map[Key]Elem.
+	KindMap
+	// KindChan is chan Elem, with its direction.
+	KindChan
+	// KindFunc is func(Params) Results.
+	KindFunc
+	// KindEllipsis is ...Elem, the type of a variadic param.
+	KindEllipsis
+	// KindGeneric is the instantiation Elem[Args].
+	KindGeneric
+	// KindOther is any other type expression, such as struct{} or interface{}.
+	KindOther
+)
+
+// TypeExpr is a parsed type expression.
+type TypeExpr struct {
+	Kind TypeKind
+	// Pkg is the package identifier and Name the name of a KindName, pkg.Name.
+	Pkg  string
+	Name string
+	// Elem is the element type of a pointer, slice, array, map, chan, ellipsis,
+	// or the instantiated type of a generic.
+	Elem *TypeExpr
+	// Key is the key type of a map.
+	Key *TypeExpr
+	// Len is the length of an array, such as 5 or ... .
+	Len string
+	// Dir is the direction of a chan.
+	Dir ast.ChanDir
+	// Args are the type arguments of a generic.
+	Args []*TypeExpr
+	// Params and Results are the params and results of a func.
+	Params  []*TypeField
+	Results []*TypeField
+	// expr is the expression of a KindOther.
+	expr ast.Expr
+}
+
+// TypeField is a param or a result of a func TypeExpr, its name can be empty.
+type TypeField struct {
+	Name string
+	Type *TypeExpr
+}
+
+// ParseTypeExpr parses the type expression s, such as map[string]*pkg.T or ...int.
+func ParseTypeExpr(s string) (*TypeExpr, error) {
+	if x := strings.TrimSpace(s); strings.HasPrefix(x, "...") {
+		elem, err := ParseTypeExpr(x[3:])
+		if err != nil {
+			return nil, err
+		}
+		return &TypeExpr{Kind: KindEllipsis, Elem: elem}, nil
+	}
+	x, err := ParseType(s)
+	if err != nil {
+		return nil, err
+	}
+	return NewTypeExpr(x), nil
+}
+
+// NewTypeExpr creates the TypeExpr of the type expression x.
+func NewTypeExpr(x ast.Expr) *TypeExpr {
+	switch x := x.(type) {
+	case *ast.Ident:
+		return &TypeExpr{Kind: KindName, Name: x.Name}
+	case *ast.SelectorExpr:
+		if pkg, ok := x.X.(*ast.Ident); ok {
+			return &TypeExpr{Kind: KindName, Pkg: pkg.Name, Name: x.Sel.Name}
+		}
+	case *ast.ParenExpr:
+		return NewTypeExpr(x.X)
+	case *ast.StarExpr:
+		return &TypeExpr{Kind: KindPointer, Elem: NewTypeExpr(x.X)}
+	case *ast.ArrayType:
+		if x.Len == nil {
+			return &TypeExpr{Kind: KindSlice, Elem: NewTypeExpr(x.Elt)}
+		}
+		return &TypeExpr{Kind: KindArray, Len: Print(x.Len), Elem: NewTypeExpr(x.Elt)}
+	case *ast.MapType:
+		return &TypeExpr{Kind: KindMap, Key: NewTypeExpr(x.Key), Elem: NewTypeExpr(x.Value)}
+	case *ast.ChanType:
+		return &TypeExpr{Kind: KindChan, Dir: x.Dir, Elem: NewTypeExpr(x.Value)}
+	case *ast.Ellipsis:
+		return &TypeExpr{Kind: KindEllipsis, Elem: NewTypeExpr(x.Elt)}
+	case *ast.FuncType:
+		return &TypeExpr{Kind: KindFunc, Params: typeFields(x.Params), Results: typeFields(x.Results)}
+	case *ast.IndexExpr:
+		return &TypeExpr{Kind: KindGeneric, Elem: NewTypeExpr(x.X), Args: []*TypeExpr{NewTypeExpr(x.Index)}}
+	case *ast.IndexListExpr:
+		ret := &TypeExpr{Kind: KindGeneric, Elem: NewTypeExpr(x.X)}
+		for _, i := range x.Indices {
+			ret.Args = append(ret.Args, NewTypeExpr(i))
+		}
+		return ret
+	}
+	return &TypeExpr{Kind: KindOther, expr: x}
+}
+
+// TypeExprOf creates the TypeExpr of the type t, packages are named with q.
+func TypeExprOf(t types.Type, q types.Qualifier) *TypeExpr {
+	return NewTypeExpr(typeExpr(t, q))
+}
+
+// String returns the source of the type expression.
+func (t *TypeExpr) String() string {
+	return Print(t.Expr())
+}
+
+// Expr returns the ast of the type expression.
+func (t *TypeExpr) Expr() ast.Expr {
+	switch t.Kind {
+	case KindName:
+		if t.Pkg != "" {
+			return &ast.SelectorExpr{X: ast.NewIdent(t.Pkg), Sel: ast.NewIdent(t.Name)}
+		}
+		return ast.NewIdent(t.Name)
+	case KindPointer:
+		return &ast.StarExpr{X: t.Elem.Expr()}
+	case KindSlice:
+		return &ast.ArrayType{Elt: t.Elem.Expr()}
+	case KindArray:
+		var l ast.Expr = &ast.Ellipsis{}
+		if t.Len != "..." {
+			x, err := ParseExpr(t.Len)
+			if err != nil {
+				x = ast.NewIdent(t.Len)
+			}
+			l = x
+		}
+		return &ast.ArrayType{Len: l, Elt: t.Elem.Expr()}
+	case KindMap:
+		return &ast.MapType{Key: t.Key.Expr(), Value: t.Elem.Expr()}
+	case KindChan:
+		elem := t.Elem.Expr()
+		// chan (<-chan T) is not chan<- (chan T).
+		if t.Dir == ast.SEND|ast.RECV && t.Elem.Kind == KindChan && t.Elem.Dir == ast.RECV {
+			elem = &ast.ParenExpr{X: elem}
+		}
+		return &ast.ChanType{Dir: t.Dir, Value: elem}
+	case KindEllipsis:
+		return &ast.Ellipsis{Elt: t.Elem.Expr()}
+	case KindFunc:
+		ret := &ast.FuncType{Params: typeFieldList(t.Params)}
+		if len(t.Results) > 0 {
+			ret.Results = typeFieldList(t.Results)
+		}
+		return ret
+	case KindGeneric:
+		var args []ast.Expr
+		for _, a := range t.Args {
+			args = append(args, a.Expr())
+		}
+		if len(args) == 1 {
+			return &ast.IndexExpr{X: t.Elem.Expr(), Index: args[0]}
+		}
+		return &ast.IndexListExpr{X: t.Elem.Expr(), Indices: args}
+	}
+	return t.expr
+}
+
+// Qualifiers returns the package identifiers of the type expression,
+// in their order of appearance.
+func (t *TypeExpr) Qualifiers() []string {
+	ret := []string{}
+	seen := map[string]bool{}
+	var walk func(t *TypeExpr)
+	walk = func(t *TypeExpr) {
+		if t == nil {
+			return
+		}
+		if t.Pkg != "" && !seen[t.Pkg] {
+			seen[t.Pkg] = true
+			ret = append(ret, t.Pkg)
+		}
+		walk(t.Key)
+		walk(t.Elem)
+		for _, a := range t.Args {
+			walk(a)
+		}
+		for _, f := range append(t.Params, t.Results...) {
+			walk(f.Type)
+		}
+		if t.expr != nil {
+			ast.Inspect(t.expr, func(n ast.Node) bool {
+				if x, ok := n.(*ast.SelectorExpr); ok {
+					if id, ok := x.X.(*ast.Ident); ok && !seen[id.Name] {
+						seen[id.Name] = true
+						ret = append(ret, id.Name)
+					}
+				}
+				return true
+			})
+		}
+	}
+	walk(t)
+	return ret
+}
+
+// typeFields returns the expanded fields of a func params or results list.
+func typeFields(l *ast.FieldList) []*TypeField {
+	var ret []*TypeField
+	if l == nil {
+		return ret
+	}
+	for _, f := range l.List {
+		t := NewTypeExpr(f.Type)
+		if len(f.Names) == 0 {
+			ret = append(ret, &TypeField{Type: t})
+		}
+		for _, n := range f.Names {
+			ret = append(ret, &TypeField{Name: n.Name, Type: t})
+		}
+	}
+	return ret
+}
+
+// typeFieldList returns the field list of fields,
+// consecutive named fields of the same type are grouped.
+func typeFieldList(fields []*TypeField) *ast.FieldList {
+	ret := &ast.FieldList{}
+	var last string
+	for _, f := range fields {
+		t := f.Type.String()
+		if n := len(ret.List); n > 0 && f.Name != "" && t == last && len(ret.List[n-1].Names) > 0 {
+			ret.List[n-1].Names = append(ret.List[n-1].Names, ast.NewIdent(f.Name))
+			continue
+		}
+		x := &ast.Field{Type: f.Type.Expr()}
+		if f.Name != "" {
+			x.Names = []*ast.Ident{ast.NewIdent(f.Name)}
+		}
+		ret.List = append(ret.List, x)
+		last = t
+	}
+	return ret
+}
@@ -0,0 +1,99 @@
+package astutil
+
+import (
+	"go/ast"
+	"go/types"
+	"strings"
+	"testing"
+)
+
+func TestParseTypeExpr(t *testing.T) {
+	for _, s := range []string{
+		"int",
+		"*pkg.T",
+		"[]*pkg.T",
+		"*[]*pkg.T",
+		"[5]int",
+		"[...]int",
+		"[N * 2]int",
+		"map[string]*T",
+		"chan *T",
+		"<-chan T",
+		"chan<- T",
+		"chan (<-chan T)",
+		"func()",
+		"func() *T",
+		"func(a, b int, c ...string) (n int, err error)",
+		"func(int, *pkg.T) error",
+		"pkg.List[int]",
+		"Map[string, []*pkg.T]",
+		"...pkg.T",
+		"struct{ Name string }",
+		"interface{ Do() }",
+	} {
+		x, err := ParseTypeExpr(s)
+		if err != nil {
+			t.Errorf("%v: %v", s, err)
+			continue
+		}
+		if got := x.String(); s != got {
+			t.Errorf("want=%v got=%v", s, got)
+		}
+	}
+	if _, err := ParseTypeExpr("map["); err == nil {
+		t.Error("want an error")
+	}
+}
+
+func TestTypeExprModel(t *testing.T) {
+	x, err := ParseTypeExpr("map[a.K][]*b.V")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if x.Kind != KindMap || x.Key.Kind != KindName || x.Key.Pkg != "a" || x.Key.Name != "K" {
+		t.Errorf("unexpected key %#v", x.Key)
+	}
+	if x.Elem.Kind != KindSlice || x.Elem.Elem.Kind != KindPointer || x.Elem.Elem.Elem.Name != "V" {
+		t.Errorf("unexpected elem %v", x.Elem)
+	}
+
+	x, _ = ParseTypeExpr("[5]<-chan int")
+	if x.Kind != KindArray || x.Len != "5" || x.Elem.Kind != KindChan || x.Elem.Dir != ast.RECV {
+		t.Errorf("unexpected type %#v", x)
+	}
+
+	x, _ = ParseTypeExpr("func(a, b int, c ...string) error")
+	if x.Kind != KindFunc || len(x.Params) != 3 || len(x.Results) != 1 {
+		t.Fatalf("unexpected type %#v", x)
+	}
+	if x.Params[1].Name != "b" || x.Params[2].Type.Kind != KindEllipsis || x.Results[0].Name != "" {
+		t.Errorf("unexpected params %v %v", x.Params[1], x.Params[2])
+	}
+
+	x, _ = ParseTypeExpr("pkg.Map[string, c.V]")
+	if x.Kind != KindGeneric || x.Elem.Pkg != "pkg" || len(x.Args) != 2 {
+		t.Errorf("unexpected type %#v", x)
+	}
+	if want, got := "pkg c", strings.Join(x.Qualifiers(), " "); want != got {
+		t.Errorf("want=%v got=%v", want, got)
+	}
+
+	x.Elem.Pkg = "other"
+	x.Args[0] = &TypeExpr{Kind: KindPointer, Elem: x.Args[0]}
+	if want, got := "other.Map[*string, c.V]", x.String(); want != got {
+		t.Errorf("want=%v got=%v", want, got)
+	}
+}
+
+func TestTypeExprOf(t *testing.T) {
+	pkg := types.NewPackage("example.com/pkg", "pkg")
+	named := types.NewNamed(types.NewTypeName(0, pkg, "T", nil), types.NewStruct(nil, nil), nil)
+	typ := types.NewMap(types.Typ[types.String], types.NewSlice(types.NewPointer(named)))
+	x := TypeExprOf(typ, (*types.Package).Name)
+	if want, got := "map[string][]*pkg.T", x.String(); want != got {
+		t.Errorf("want=%v got=%v", want, got)
+	}
+	if x.Elem.Elem.Elem.Pkg != "pkg" {
+		t.Errorf("unexpected type %#v", x.Elem.Elem.Elem)
+	}
+}
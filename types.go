@@ -0,0 +1,60 @@
+package astutil
+
+import (
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/loader"
+)
+
+// GetType returns the types.Type of the type named s in given package.
+// s can be a local name (T), a pointer (*T),
+// a qualified name of an imported package (pkg.T, *pkg.T)
+// or a predeclared type (error, string...).
+// It returns nil when the type is not found.
+func GetType(p *loader.PackageInfo, s string) types.Type {
+	s = strings.TrimSpace(s)
+	if IsAPointedType(s) {
+		t := GetType(p, GetUnpointedType(s))
+		if t == nil {
+			return nil
+		}
+		return types.NewPointer(t)
+	}
+	var obj types.Object
+	if x := strings.Split(s, "."); len(x) == 2 {
+		pkg := getImportedPkg(p, x[0])
+		if pkg == nil {
+			return nil
+		}
+		obj = pkg.Scope().Lookup(x[1])
+	} else {
+		if p.Pkg != nil {
+			obj = p.Pkg.Scope().Lookup(s)
+		}
+		if obj == nil {
+			obj = types.Universe.Lookup(s)
+		}
+	}
+	if x, ok := obj.(*types.TypeName); ok {
+		return x.Type()
+	}
+	return nil
+}
+
+// getImportedPkg returns the package imported by p with identifier name.
+func getImportedPkg(p *loader.PackageInfo, name string) *types.Package {
+	if p.Pkg == nil {
+		return nil
+	}
+	path := GetImportPath(p, name)
+	for _, pkg := range p.Pkg.Imports() {
+		if path != "" && pkg.Path() == path {
+			return pkg
+		}
+		if path == "" && (pkg.Name() == name || pkg.Path() == name) {
+			return pkg
+		}
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package astutil
+
+import (
+	"go/ast"
+	"go/token"
+)
+
+// WrapperHook returns the statements inserted into a generated wrapper.
+// results are the names of the variables receiving the results of the call,
+// it is empty for the statements inserted before the call.
+// n allocates the variable names of the wrapper body.
+type WrapperHook func(s *Signature, results []string, n *Namer) []ast.Stmt
+
+// WrapperOptions configures the generation of a wrapper.
+type WrapperOptions struct {
+	// Recv is the receiver of the wrapper, a func is generated when it is nil.
+	Recv *ast.Field
+	// Name of the wrapper, it defaults to the name of the wrapped method.
+	Name string
+	// Target is the expression whose method is invoked, the wrapper calls Target.Method(...).
+	Target ast.Expr
+	// Before is invoked to insert statements before the call.
+	Before WrapperHook
+	// After is invoked to insert statements after the call.
+	After WrapperHook
+}
+
+// GenerateWrapper generates a method with the signature of m forwarding the call to o.Target.
+// m is a named func like node supported by GetFunc,
+// it returns nil for an unsupported node or a func without name.
+func GenerateWrapper(m ast.Node, o WrapperOptions) *ast.FuncDecl {
+	f, err := GetFunc(m)
+	if err != nil || f.Name == "" {
+		return nil
+	}
+	return GenerateSignatureWrapper(f.Name, GetSignature(f), o)
+}
+
+// GenerateInterfaceMethodWrapper generates a method with the signature of the interface method m
+// forwarding the call to o.Target.
+// It returns nil when m is not a method, such as an embedded interface,
+// the caller should skip such fields.
+func GenerateInterfaceMethodWrapper(m *ast.Field, o WrapperOptions) *ast.FuncDecl {
+	if _, ok := m.Type.(*ast.FuncType); !ok || len(m.Names) == 0 {
+		return nil
+	}
+	return GenerateWrapper(m, o)
+}
+
+// GenerateSignatureWrapper generates a method with signature s forwarding the call to o.Target.Name.
+func GenerateSignatureWrapper(name string, s *Signature, o WrapperOptions) *ast.FuncDecl {
+	ret := &ast.FuncDecl{
+		Name: ast.NewIdent(name),
+		Type: s.FuncType(),
+		Body: &ast.BlockStmt{},
+	}
+	if o.Name != "" {
+		ret.Name = ast.NewIdent(o.Name)
+	}
+	namer := NewNamer()
+	for _, p := range s.all() {
+		namer.Reserve(p.Name)
+	}
+	if o.Recv != nil {
+		ret.Recv = &ast.FieldList{List: []*ast.Field{o.Recv}}
+		for _, n := range o.Recv.Names {
+			namer.Reserve(n.Name)
+		}
+	}
+
+	if o.Before != nil {
+		ret.Body.List = append(ret.Body.List, o.Before(s, nil, namer)...)
+	}
+
+	call := &ast.CallExpr{
+		Fun: &ast.SelectorExpr{X: o.Target, Sel: ast.NewIdent(name)},
+	}
+	for _, p := range s.Params {
+		call.Args = append(call.Args, ast.NewIdent(p.Name))
+	}
+	if s.HasEllipse() {
+		call.Ellipsis = 1
+	}
+
+	var results []string
+	var resultExprs []ast.Expr
+	for range s.Results {
+		x := namer.Next("retVar")
+		results = append(results, x)
+		resultExprs = append(resultExprs, ast.NewIdent(x))
+	}
+	if len(results) > 0 {
+		ret.Body.List = append(ret.Body.List, &ast.AssignStmt{
+			Lhs: resultExprs,
+			Tok: token.DEFINE,
+			Rhs: []ast.Expr{call},
+		})
+	} else {
+		ret.Body.List = append(ret.Body.List, &ast.ExprStmt{X: call})
+	}
+
+	if o.After != nil {
+		ret.Body.List = append(ret.Body.List, o.After(s, results, namer)...)
+	}
+
+	if len(results) > 0 {
+		ret.Body.List = append(ret.Body.List, &ast.ReturnStmt{Results: resultExprs})
+	}
+	return ret
+}
@@ -0,0 +1,61 @@
+package astutil
+
+import (
+	"go/ast"
+	"testing"
+)
+
+func TestGenerateWrapper(t *testing.T) {
+	y := getFuncDecl(`func (t *T) Do(a string, b ...int) (int, error) {}`)
+	got := Print(GenerateWrapper(y, WrapperOptions{
+		Recv:   &ast.Field{Names: []*ast.Ident{ast.NewIdent("w")}, Type: &ast.StarExpr{X: ast.NewIdent("W")}},
+		Target: &ast.SelectorExpr{X: ast.NewIdent("w"), Sel: ast.NewIdent("next")},
+		Before: func(s *Signature, results []string, n *Namer) []ast.Stmt {
+			return []ast.Stmt{&ast.ExprStmt{X: &ast.CallExpr{Fun: ast.NewIdent("before")}}}
+		},
+		After: func(s *Signature, results []string, n *Namer) []ast.Stmt {
+			return []ast.Stmt{&ast.ExprStmt{X: &ast.CallExpr{
+				Fun:  ast.NewIdent("after"),
+				Args: []ast.Expr{ast.NewIdent(results[1])},
+			}}}
+		},
+	}))
+	want := `func (w *W) Do(a string, b ...int) (int, error) {
+	before()
+	retVar0, retVar1 := w.next.Do(a, b...)
+	after(retVar1)
+	return retVar0, retVar1
+}`
+	if want != got {
+		t.Errorf("want=\n%v\ngot=\n%v", want, got)
+	}
+}
+
+func TestGenerateInterfaceMethodWrapper(t *testing.T) {
+	y := getStructDecl(`type I interface{ Do(string, int) }`)
+	m := y.Type.(*ast.InterfaceType).Methods.List[0]
+	got := Print(GenerateInterfaceMethodWrapper(m, WrapperOptions{
+		Name:   "Wrapped",
+		Target: ast.NewIdent("x"),
+	}))
+	want := `func Wrapped(arg0 string, arg1 int) {
+	x.Do(arg0, arg1)
+}`
+	if want != got {
+		t.Errorf("want=\n%v\ngot=\n%v", want, got)
+	}
+}
+
+func TestGenerateInterfaceMethodWrapperEmbedded(t *testing.T) {
+	y := getStructDecl(`type I interface{
+	io.Reader
+	Do()
+}`)
+	l := y.Type.(*ast.InterfaceType).Methods.List
+	if got := GenerateInterfaceMethodWrapper(l[0], WrapperOptions{Target: ast.NewIdent("x")}); got != nil {
+		t.Errorf("want nil got %v", Print(got))
+	}
+	if got := GenerateInterfaceMethodWrapper(l[1], WrapperOptions{Target: ast.NewIdent("x")}); got == nil {
+		t.Errorf("want a wrapper")
+	}
+}
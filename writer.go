@@ -0,0 +1,186 @@
+package astutil
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Writer writes the files of a generator.
+// It is safe for concurrent use.
+type Writer struct {
+	// Generator is the name of the generator written in the header of the files.
+	Generator string
+	// Check enables the check mode, files are not written nor removed,
+	// instead the outdated files are reported by Err.
+	Check bool
+
+	mu       sync.Mutex
+	written  map[string]bool
+	outdated []string
+	diffs    bytes.Buffer
+}
+
+// OutdatedError lists the generated files which are not up to date.
+type OutdatedError struct {
+	Files []string
+	// Diff is the unified diff of the files on disk to the generated files.
+	Diff string
+}
+
+func (e *OutdatedError) Error() string {
+	return fmt.Sprintf("generated files are outdated:\n%v\n%v", strings.Join(e.Files, "\n"), e.Diff)
+}
+
+// NewWriter creates a Writer for the generator named generator.
+func NewWriter(generator string) *Writer {
+	return &Writer{Generator: generator, written: map[string]bool{}}
+}
+
+// Header returns the standard header of the generated files.
+func (w *Writer) Header() string {
+	return fmt.Sprintf("// Code generated by %v; DO NOT EDIT.", w.Generator)
+}
+
+// Write the go source src to the file name.
+// src is prefixed with the header when it does not already have it, then formatted.
+// The file is left untouched when its content is unchanged,
+// otherwise it is written atomically.
+// In check mode, a file whose content would change is recorded as outdated.
+func (w *Writer) Write(name string, src []byte) error {
+	if !hasHeader(src, w.Header()) {
+		src = append([]byte(w.Header()+"\n\n"), src...)
+	}
+	src, err := format.Source(src)
+	if err != nil {
+		return fmt.Errorf("%v: %v", name, err)
+	}
+	old, err := ioutil.ReadFile(name)
+	if err == nil && bytes.Equal(old, src) {
+		return w.markWritten(name)
+	}
+	if w.Check {
+		w.addOutdated(name, old, src)
+		return w.markWritten(name)
+	}
+	if err := writeFileAtomic(name, src); err != nil {
+		return err
+	}
+	return w.markWritten(name)
+}
+
+// Err returns an *OutdatedError listing the outdated files met in check mode,
+// it is nil when every file is up to date.
+func (w *Writer) Err() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.outdated) == 0 {
+		return nil
+	}
+	return &OutdatedError{
+		Files: append([]string{}, w.outdated...),
+		Diff:  w.diffs.String(),
+	}
+}
+
+func (w *Writer) addOutdated(name string, old, src []byte) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.outdated = append(w.outdated, name)
+	w.diffs.WriteString(UnifiedDiff(name+" (on disk)", name+" (generated)", old, src))
+}
+
+// Clean removes the go files of the directory dir
+// generated by the same generator which were not written by w.
+// In check mode, such files are recorded as outdated.
+func (w *Writer) Clean(dir string) error {
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		abs, err := filepath.Abs(f)
+		if err != nil {
+			return err
+		}
+		w.mu.Lock()
+		written := w.written[abs]
+		w.mu.Unlock()
+		if written {
+			continue
+		}
+		src, err := ioutil.ReadFile(f)
+		if err != nil {
+			return err
+		}
+		if hasHeader(src, w.Header()) {
+			if w.Check {
+				w.addOutdated(f, src, nil)
+			} else if err := os.Remove(f); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (w *Writer) markWritten(name string) error {
+	abs, err := filepath.Abs(name)
+	if err != nil {
+		return err
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.written == nil {
+		w.written = map[string]bool{}
+	}
+	w.written[abs] = true
+	return nil
+}
+
+// hasHeader returns true if header is a line of src before the package clause.
+func hasHeader(src []byte, header string) bool {
+	s := bufio.NewScanner(bytes.NewReader(src))
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if line == header {
+			return true
+		}
+		if strings.HasPrefix(line, "package ") {
+			return false
+		}
+	}
+	return false
+}
+
+// writeFileAtomic writes data to a temporary file renamed to name,
+// the mode of an existing file is preserved.
+func writeFileAtomic(name string, data []byte) error {
+	mode := os.FileMode(0644)
+	if s, err := os.Stat(name); err == nil {
+		mode = s.Mode().Perm()
+	}
+	f, err := ioutil.TempFile(filepath.Dir(name), "."+filepath.Base(name)+".")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Chmod(mode); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), name)
+}
@@ -0,0 +1,159 @@
+package astutil
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWriter(t *testing.T) {
+	dir, err := ioutil.TempDir("", "astutil")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	name := filepath.Join(dir, "a_gen.go")
+	w := NewWriter("astutil")
+	if err := w.Write(name, []byte("package a\nvar A=1")); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ioutil.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "// Code generated by astutil; DO NOT EDIT.\n\npackage a\n\nvar A = 1\n"
+	if want != string(got) {
+		t.Errorf("want=\n%v\ngot=\n%s", want, got)
+	}
+
+	past := time.Now().Add(-time.Hour).Truncate(time.Second)
+	if err := os.Chtimes(name, past, past); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write(name, got); err != nil {
+		t.Fatal(err)
+	}
+	if s, err := os.Stat(name); err != nil {
+		t.Fatal(err)
+	} else if !s.ModTime().Equal(past) {
+		t.Errorf("an unchanged file must not be written")
+	}
+
+	if err := w.Write(name, []byte("package a\nvar A=")); err == nil {
+		t.Errorf("want an error for an invalid source")
+	}
+
+	if err := os.Chmod(name, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write(name, []byte("package a\nvar A=2")); err != nil {
+		t.Fatal(err)
+	}
+	if s, err := os.Stat(name); err != nil {
+		t.Fatal(err)
+	} else if s.Mode().Perm() != 0600 {
+		t.Errorf("want %v got %v", os.FileMode(0600), s.Mode().Perm())
+	}
+
+	missing := filepath.Join(dir, "missing", "b_gen.go")
+	if err := w.Write(missing, []byte("package a\n")); err == nil {
+		t.Errorf("want an error for a missing directory")
+	}
+	abs, err := filepath.Abs(missing)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w.written[abs] {
+		t.Errorf("a file which failed to be written must not be recorded as written")
+	}
+}
+
+func TestWriterClean(t *testing.T) {
+	dir, err := ioutil.TempDir("", "astutil")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"stale_gen.go": "// Code generated by astutil; DO NOT EDIT.\n\npackage a\n",
+		"other_gen.go": "// Code generated by other; DO NOT EDIT.\n\npackage a\n",
+		"a.go":         "package a\n",
+	}
+	for name, src := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	w := NewWriter("astutil")
+	if err := w.Write(filepath.Join(dir, "a_gen.go"), []byte("package a\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Clean(dir); err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]bool{
+		"stale_gen.go": false,
+		"other_gen.go": true,
+		"a.go":         true,
+		"a_gen.go":     true,
+	} {
+		_, err := os.Stat(filepath.Join(dir, name))
+		if got := err == nil; want != got {
+			t.Errorf("%v: want exists=%v got %v", name, want, got)
+		}
+	}
+}
+
+func TestWriterCheck(t *testing.T) {
+	dir, err := ioutil.TempDir("", "astutil")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	name := filepath.Join(dir, "a_gen.go")
+	src := []byte("package a\n\nvar A = 1\n")
+	w := NewWriter("astutil")
+	if err := w.Write(name, src); err != nil {
+		t.Fatal(err)
+	}
+
+	before, err := ioutil.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w = NewWriter("astutil")
+	w.Check = true
+	if err := w.Write(name, src); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Err(); err != nil {
+		t.Errorf("want no error got %v", err)
+	}
+
+	if err := w.Write(name, []byte("package a\n\nvar A = 2\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write(filepath.Join(dir, "b_gen.go"), src); err != nil {
+		t.Fatal(err)
+	}
+	err = w.Err()
+	if err == nil {
+		t.Fatal("want an error")
+	}
+	outdated := err.(*OutdatedError)
+	if len(outdated.Files) != 2 {
+		t.Errorf("want %v outdated files got %v", 2, outdated.Files)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "b_gen.go")); err == nil {
+		t.Errorf("a file must not be written in check mode")
+	}
+	if got, _ := ioutil.ReadFile(name); string(got) != string(before) {
+		t.Errorf("a file must not be written in check mode")
+	}
+}